@@ -0,0 +1,68 @@
+package tps
+
+import (
+	"errors"
+	"math"
+)
+
+// SetBaselineGrid turns on the baseline grid. Instead of sitting in the middle
+// of its cell, each wrapped line of text sits on the baseline of the last grid
+// line it takes up, offset above the bottom of the grid line. Text of any size
+// in adjacent columns then shares baselines, and a heading in a block of 2 or
+// more lines stays on the rhythm of the body text around it:
+//
+//   r.SetBaselineGrid(3)
+//   r.AddBlock("heading", 6, r.Grid.RhythmLines(24))
+//
+// The vertical alignment of styles is ignored on the baseline grid.
+func (r *Report) SetBaselineGrid(offset float64) error {
+	if offset < 0 || offset >= r.Grid.LineHeight {
+		return errors.New("Baseline offset must be at least 0 and less than the line height")
+	}
+	r.Grid.BaselineGrid = true
+	r.Grid.BaselineOffset = offset
+	return nil
+}
+
+// RhythmLines returns the # of grid lines a font size in points needs so its
+// lines of text stay on the vertical rhythm, for use as a Block.Height.
+func (g *Grid) RhythmLines(fontSize float64) int {
+	if g.LineHeight <= 0 {
+		return 1
+	}
+	size := fontSize / unitPoints[g.Unit]
+	// small tolerance so exact fits are not lost to float rounding
+	lines := int(math.Ceil(size/g.LineHeight - 1e-9))
+	if lines < 1 {
+		return 1
+	}
+	return lines
+}
+
+// baseline returns the y of the baseline of wrapped line i of text placed at
+// top, where each wrapped line is height tall.
+func (g *Grid) baseline(top, height float64, i int) float64 {
+	return top + height*float64(i+1) - g.BaselineOffset
+}
+
+// placeOnBaselines draws wrapped lines of text on the baseline grid with the
+// font of the style already set. The fill and border go around all lines.
+func (r *Report) placeOnBaselines(point Point, cell Cell, style Style, lines []string) {
+	if style.Fill || style.Border != 0 {
+		r.Pdf.SetXY(point.X, point.Y)
+		height := cell.Height * float64(len(lines))
+		r.Pdf.CellFormat(cell.Width, height, "", style.convertBorder(), 0, "", style.Fill, 0, "")
+	}
+	margin := r.padding(style)
+	for i, line := range lines {
+		x := point.X + margin
+		width := r.Pdf.GetStringWidth(line)
+		switch {
+		case style.Alignment&AlignCenter > 0:
+			x += (cell.Width - margin*2 - width) / 2
+		case style.Alignment&AlignRight > 0:
+			x += cell.Width - margin*2 - width
+		}
+		r.Pdf.Text(x, r.Grid.baseline(point.Y, cell.Height, i), line)
+	}
+}
@@ -0,0 +1,64 @@
+package tps
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRhythmLines(t *testing.T) {
+	g := newGrid()
+	for size, lines := range map[float64]int{8: 1, 12: 1, 13: 2, 24: 2, 30: 3} {
+		if got := g.RhythmLines(size); got != lines {
+			t.Errorf("RhythmLines returned the wrong # of lines for %g. Got %d expected %d", size, got, lines)
+		}
+	}
+	g.Unit = UnitMm
+	g.LineHeight = 5
+	if got := g.RhythmLines(24); got != 2 {
+		t.Errorf("RhythmLines did not convert the font size to the unit. Got %d expected %d", got, 2)
+	}
+}
+
+func TestBaselineGrid(t *testing.T) {
+	r, err := NewReport(
+		WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36, 12, 12, 12),
+		WithBaselineGrid(3),
+		WithStyles(map[string]Style{
+			"heading": {FontFamily: "Helvetica", FontSize: 24, Alignment: AlignLeft | AlignTop},
+			"body":    {FontFamily: "Helvetica", FontSize: 10, Alignment: AlignRight | AlignMiddle},
+		}),
+		WithBlocks(map[string]Block{"heading": {6, 2}, "body": {6, 1}}),
+	)
+	if err != nil {
+		t.Fatalf("NewReport returned an error: %v", err)
+	}
+	r.Pdf.SetCompression(false)
+	r.AddPage()
+	if _, err = r.Content(1, 1, "heading", "heading", "Heading"); err != nil {
+		t.Fatalf("Content returned an error: %v", err)
+	}
+	lineCount, err := r.Content(7, 2, "body", "body", "Body")
+	if err != nil || lineCount != 1 {
+		t.Fatalf("Content returned the wrong line count on the baseline grid. Got %d error %v", lineCount, err)
+	}
+	data, err := r.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+
+	// the heading on lines 1-2 and the body on line 2 share the baseline 3pt
+	// above the bottom of line 2, 792 - 36 - 24 + 3 from the bottom of the page
+	texts := regexp.MustCompile(`BT [\d.]+ ([\d.]+) Td \((\w+)\) Tj ET`).FindAllSubmatch(data, -1)
+	if len(texts) != 2 {
+		t.Fatalf("Content did not draw the text on baselines. Got %q", texts)
+	}
+	for _, text := range texts {
+		if string(text[1]) != "735.00" {
+			t.Errorf("Content did not place %s on the baseline. Got %s expected 735.00", text[2], text[1])
+		}
+	}
+
+	if err = r.SetBaselineGrid(12); err == nil {
+		t.Error("SetBaselineGrid did not reject an offset of the line height.")
+	}
+}
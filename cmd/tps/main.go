@@ -0,0 +1,182 @@
+// Command tps renders report spec files to PDF and helps with fonts and grids
+// without writing Go.
+//
+// Usage:
+//
+//	tps render spec.json -o out.pdf
+//	tps fonts compile dir/ --encoding cp1252
+//	tps grid --page letter --columns 12 --gutter 12
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/glennyonemitsu/tps"
+)
+
+const usage = `Usage:
+  tps render <spec> [-o out.pdf]
+  tps fonts compile <dir> [--encoding cp1252]
+  tps grid [--page letter] [--columns 12] [--gutter 12] ...
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command in args, writing any output to stdout.
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "render":
+		return render(args[1:], stdout)
+	case "fonts":
+		if len(args) < 2 || args[1] != "compile" {
+			return errors.New(usage)
+		}
+		return compileFonts(args[2:], stdout)
+	case "grid":
+		return grid(args[1:], stdout)
+	}
+	return fmt.Errorf("Unknown command: %s\n%s", args[0], usage)
+}
+
+// render loads a spec file and writes the PDF. The output defaults to the spec
+// filename with a .pdf extension, and "-" writes to stdout. A relative font
+// path in the spec is relative to the spec file, so it renders the same from
+// any working directory, such as a cron job's.
+func render(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	output := flags.String("o", "", "output PDF file, - for stdout")
+	positional, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("render needs exactly one spec file")
+	}
+	filename := positional[0]
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	spec, err := tps.ReadSpec(file)
+	if err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	if spec.FontPath != "" && !filepath.IsAbs(spec.FontPath) {
+		spec.FontPath = filepath.Join(filepath.Dir(filename), spec.FontPath)
+	}
+	r, err := spec.Report()
+	if err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+
+	switch *output {
+	case "-":
+		_, err = r.WriteTo(stdout)
+		return err
+	case "":
+		*output = strings.TrimSuffix(filename, path.Ext(filename)) + ".pdf"
+	}
+	return r.Save(*output)
+}
+
+// compileFonts compiles every TrueType and OpenType font in a directory into
+// its _compiled subdirectory.
+func compileFonts(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("fonts compile", flag.ContinueOnError)
+	encoding := flags.String("encoding", "cp1252", "font encoding")
+	positional, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errors.New("fonts compile needs exactly one font directory")
+	}
+	dir := positional[0]
+
+	r, err := tps.NewReport(tps.WithFontPath(dir))
+	if err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		ext := strings.ToLower(path.Ext(file.Name()))
+		if file.IsDir() || (ext != ".ttf" && ext != ".otf") {
+			continue
+		}
+		compiled, err := r.CompileFont(file.Name(), *encoding)
+		if err != nil {
+			return fmt.Errorf("%s: %v", file.Name(), err)
+		}
+		fmt.Fprintf(stdout, "%s -> %s\n", file.Name(), path.Join(r.FontCompiledPath, compiled))
+	}
+	return nil
+}
+
+// grid prints the specs calculated for a grid.
+func grid(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("grid", flag.ContinueOnError)
+	spec := tps.Spec{}
+	flags.StringVar(&spec.Grid.Orientation, "orientation", "portrait", "page orientation")
+	flags.StringVar(&spec.Grid.PageSize, "page", "letter", "page size")
+	flags.StringVar(&spec.Grid.Unit, "unit", "pt", "unit of measurement")
+	flags.Float64Var(&spec.Grid.Margin, "margin", 36, "page margin")
+	flags.IntVar(&spec.Grid.Columns, "columns", 12, "# of columns")
+	flags.Float64Var(&spec.Grid.Gutter, "gutter", 12, "space between columns")
+	flags.Float64Var(&spec.Grid.LineHeight, "line-height", 12, "line height")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+
+	r, err := spec.Report()
+	if err != nil {
+		return err
+	}
+	g := r.Grid
+	fmt.Fprintf(stdout, "PageWidth    %g\n", g.PageWidth)
+	fmt.Fprintf(stdout, "PageHeight   %g\n", g.PageHeight)
+	fmt.Fprintf(stdout, "ColumnCount  %d\n", g.ColumnCount)
+	fmt.Fprintf(stdout, "ColumnWidth  %g\n", g.ColumnWidth)
+	fmt.Fprintf(stdout, "GutterCount  %d\n", g.GutterCount)
+	fmt.Fprintf(stdout, "GutterWidth  %g\n", g.GutterWidth)
+	fmt.Fprintf(stdout, "LineCount    %d\n", g.LineCount())
+	return nil
+}
+
+// parse parses flags given before or after the positional arguments, which
+// the flag package stops at by itself, and returns the positional arguments.
+func parse(flags *flag.FlagSet, args []string) ([]string, error) {
+	flags.SetOutput(ioutil.Discard)
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestGrid(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{"grid", "--page", "letter", "--columns", "12", "--gutter", "12"}, &out)
+	if err != nil {
+		t.Fatalf("grid returned an error: %v", err)
+	}
+	for _, line := range []string{"ColumnWidth  34", "GutterCount  11"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("grid output missing %q. Got:\n%s", line, out.String())
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "spec.yaml")
+	data := `
+grid: {orientation: portrait, pageSize: letter, unit: pt, margin: 36, columns: 12, gutter: 12, lineHeight: 12}
+styles: {body: {font: Helvetica, size: 10}}
+blocks: {full: {width: 12, height: 1}}
+content: [{x: 1, y: 1, block: full, style: body, text: Hello}]
+`
+	if err := ioutil.WriteFile(spec, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	output := filepath.Join(dir, "out.pdf")
+	if err := run([]string{"render", spec, "-o", output}, ioutil.Discard); err != nil {
+		t.Fatalf("render returned an error: %v", err)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("render did not write the PDF: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"render", "-o", "-", spec}, &out); err != nil {
+		t.Fatalf("render to stdout returned an error: %v", err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), []byte("%PDF-")) {
+		t.Errorf("render did not write the PDF to stdout")
+	}
+}
+
+func TestRenderFontPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "fonts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "fonts", "Go.ttf"), goregular.TTF, 0644); err != nil {
+		t.Fatal(err)
+	}
+	spec := filepath.Join(dir, "spec.yaml")
+	data := `
+grid: {orientation: portrait, pageSize: letter, unit: pt, margin: 36, columns: 12, gutter: 12, lineHeight: 12}
+fontPath: fonts
+fonts: [{file: Go.ttf, encoding: cp1252}]
+styles: {body: {font: Go, size: 10}}
+blocks: {full: {width: 12, height: 1}}
+content: [{x: 1, y: 1, block: full, style: body, text: Hello}]
+`
+	if err := ioutil.WriteFile(spec, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the font path is found next to the spec, not in the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err = run([]string{"render", spec, "-o", filepath.Join(dir, "out.pdf")}, ioutil.Discard); err != nil {
+		t.Fatalf("render returned an error from another working directory: %v", err)
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	if err := run([]string{"paint"}, ioutil.Discard); err == nil {
+		t.Errorf("run did not return an error for an unknown command")
+	}
+}
@@ -0,0 +1,84 @@
+package tps
+
+// Colors used by the debug overlay.
+var (
+	debugColumnColor = Color{0, 160, 255}
+	debugLineColor   = Color{180, 220, 255}
+	debugMarginColor = Color{255, 80, 80}
+	debugBlockColor  = Color{0, 110, 200}
+)
+
+// SetDebug turns the debug overlay on or off. While on, DrawGrid() is called
+// on every page added and every placement is outlined and labeled with its
+// block name.
+func (r *Report) SetDebug(debug bool) {
+	r.debug = debug
+}
+
+// DrawGrid draws the column bands, a line every Grid.LineHeight and the margin
+// box on the current page to help check layouts. The columns are drawn
+// translucent so content placed before this call is still visible.
+func (r *Report) DrawGrid() {
+	defer r.saveDrawing()()
+	g := r.Grid
+	top, right, bottom, left := g.pageMargins()
+	width := g.PageWidth - left - right
+	height := g.PageHeight - top - bottom
+
+	r.Pdf.SetAlpha(0.15, "Normal")
+	r.Pdf.SetFillColor(debugColumnColor.R, debugColumnColor.G, debugColumnColor.B)
+	for x := 1; x <= g.ColumnCount; x++ {
+		point := g.GetPoint(x, 1)
+		r.Pdf.Rect(point.X, top, g.ColumnWidth, height, "F")
+	}
+	r.Pdf.SetAlpha(1, "Normal")
+
+	r.Pdf.SetLineWidth(r.Pdf.PointConvert(0.25))
+	r.Pdf.SetDrawColor(debugLineColor.R, debugLineColor.G, debugLineColor.B)
+	for y := 0; y <= g.LineCount(); y++ {
+		lineY := top + float64(y)*g.LineHeight
+		r.Pdf.Line(left, lineY, left+width, lineY)
+	}
+
+	r.Pdf.SetDrawColor(debugMarginColor.R, debugMarginColor.G, debugMarginColor.B)
+	r.Pdf.Rect(left, top, width, height, "D")
+}
+
+// outline draws the box of a placement taking up lineCount wrapped lines and
+// labels it when debugging.
+func (r *Report) outline(x, y int, block Block, lineCount int, label string) {
+	if !r.debug {
+		return
+	}
+	if lineCount == 0 {
+		lineCount = 1
+	}
+	point := r.Grid.GetPoint(x, y)
+	cell := r.Grid.GetCell(block)
+
+	defer r.saveDrawing()()
+	r.Pdf.SetLineWidth(r.Pdf.PointConvert(0.5))
+	r.Pdf.SetDrawColor(debugBlockColor.R, debugBlockColor.G, debugBlockColor.B)
+	r.Pdf.Rect(point.X, point.Y, cell.Width, cell.Height*float64(lineCount), "D")
+	if label != "" {
+		r.Pdf.SetFont("Helvetica", "", 5)
+		r.Pdf.SetTextColor(debugBlockColor.R, debugBlockColor.G, debugBlockColor.B)
+		r.Pdf.Text(point.X+r.Pdf.PointConvert(1), point.Y+r.Pdf.PointConvert(5), label)
+	}
+}
+
+// saveDrawing saves the colors and line width and returns a func restoring
+// them, so the overlay does not leak into content. The font is not restored
+// since every placement sets its own.
+func (r *Report) saveDrawing() func() {
+	dr, dg, db := r.Pdf.GetDrawColor()
+	fr, fg, fb := r.Pdf.GetFillColor()
+	tr, tg, tb := r.Pdf.GetTextColor()
+	lineWidth := r.Pdf.GetLineWidth()
+	return func() {
+		r.Pdf.SetDrawColor(dr, dg, db)
+		r.Pdf.SetFillColor(fr, fg, fb)
+		r.Pdf.SetTextColor(tr, tg, tb)
+		r.Pdf.SetLineWidth(lineWidth)
+	}
+}
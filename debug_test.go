@@ -0,0 +1,21 @@
+package tps
+
+import (
+	"testing"
+)
+
+func TestDebugOverlay(t *testing.T) {
+	r := newFlowReport(t)
+	r.SetDebug(true)
+	r.Pdf.SetDrawColor(1, 2, 3)
+	r.AddPage()
+	if _, err := r.Content(1, 1, "full", "body", "Hello"); err != nil {
+		t.Fatalf("Content returned an error: %v", err)
+	}
+	if red, green, blue := r.Pdf.GetDrawColor(); red != 1 || green != 2 || blue != 3 {
+		t.Errorf("Debug overlay did not restore draw color. Got %d %d %d", red, green, blue)
+	}
+	if _, err := r.Bytes(); err != nil {
+		t.Errorf("Bytes returned an error with the debug overlay: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package tps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Cursor is the grid position where Report.Flow places its next content. X is
+// the column and Y is the line, both starting at 1 like Report.Content().
+type Cursor struct {
+	X, Y int
+}
+
+// SetCursor moves the flow cursor to the x, y grid coordinates on the current
+// page.
+func (r *Report) SetCursor(x, y int) {
+	r.Cursor = Cursor{X: x, Y: y}
+}
+
+// Flow places text at the cursor using the named block and style, then moves
+// the cursor down by the lines taken up. When the next line of text would
+// cross the bottom margin a new page is added and the remaining text carries
+// over to the top of it. Returns the total # of lines taken up across all
+// pages.
+func (r *Report) Flow(
+	blockName string,
+	styleName string,
+	text string,
+	overrides ...StyleOption,
+) (lineCount int, err error) {
+	block, style, err := r.lookup(blockName, styleName, overrides...)
+	if err != nil {
+		return 0, err
+	}
+	if err = r.checkGlyphs(style, text); err != nil {
+		return 0, err
+	}
+	return r.flowText(block, style, text, blockName)
+}
+
+// FlowRich places markup at the cursor like Flow(), using the tags described
+// in RichContent().
+func (r *Report) FlowRich(
+	blockName string,
+	styleName string,
+	markup string,
+	overrides ...StyleOption,
+) (lineCount int, err error) {
+	block, style, err := r.lookup(blockName, styleName, overrides...)
+	if err != nil {
+		return 0, err
+	}
+	return r.flowRich(block, style, markup, blockName)
+}
+
+// flowText does the work of Flow() once the block and style are resolved.
+func (r *Report) flowText(block Block, style Style, text, label string) (int, error) {
+	lines, err := r.wrap(block, style, text)
+	if err != nil {
+		return 0, err
+	}
+	return r.flowLines(block, len(lines), label, func(from, to int) {
+		chunk := strings.Join(lines[from:to], "\n")
+		r.place(r.Cursor.X, r.Cursor.Y, block, style, chunk, label)
+	})
+}
+
+// flowRich does the work of FlowRich() once the block and style are resolved.
+func (r *Report) flowRich(block Block, style Style, markup, label string) (int, error) {
+	lines, err := r.layoutRichContent(block, style, markup)
+	if err != nil {
+		return 0, err
+	}
+	return r.flowLines(block, len(lines), label, func(from, to int) {
+		r.drawRich(r.Cursor.X, r.Cursor.Y, block, style, lines[from:to], label)
+	})
+}
+
+// flowLines moves count wrapped lines through the pages from the cursor. draw
+// is called to place the lines from one index up to another at the cursor,
+// as many as fit before the bottom margin, adding pages as needed.
+func (r *Report) flowLines(block Block, count int, label string, draw func(from, to int)) (lineCount int, err error) {
+	pageLines := r.Grid.LineCount()
+	if block.Height > pageLines {
+		return 0, fmt.Errorf("Block is taller than the page: %s", label)
+	}
+	r.startPage()
+
+	for from := 0; from < count; {
+		available := (pageLines - r.Cursor.Y + 1) / block.Height
+		if available <= 0 {
+			r.AddPage()
+			continue
+		}
+		to := from + available
+		if to > count {
+			to = count
+		}
+		draw(from, to)
+		used := (to - from) * block.Height
+		r.Cursor.Y += used
+		lineCount += used
+		from = to
+	}
+	return lineCount, nil
+}
@@ -0,0 +1,63 @@
+package tps
+
+import (
+	"strings"
+	"testing"
+)
+
+func newFlowReport(t *testing.T) *Report {
+	r, err := NewReport(
+		WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36.0, 12, 12.0, 12.0),
+		WithStyles(map[string]Style{"body": {FontFamily: "Helvetica", FontSize: 10, Alignment: AlignLeft}}),
+		WithBlocks(map[string]Block{"full": {12, 1}, "double": {12, 2}}),
+	)
+	if err != nil {
+		t.Fatalf("NewReport returned an error: %v", err)
+	}
+	return r
+}
+
+func TestFlow(t *testing.T) {
+	r := newFlowReport(t)
+	lineCount, err := r.Flow("full", "body", "one\ntwo\nthree")
+	if err != nil {
+		t.Fatalf("Flow returned an error: %v", err)
+	}
+	if lineCount != 3 {
+		t.Errorf("Flow returned wrong line count. Got %d expected %d", lineCount, 3)
+	}
+	if r.Cursor.Y != 4 {
+		t.Errorf("Flow did not advance cursor. Got %d expected %d", r.Cursor.Y, 4)
+	}
+
+	lineCount, _ = r.Flow("double", "body", "four")
+	if lineCount != 2 || r.Cursor.Y != 6 {
+		t.Errorf("Flow did not use block height. Got %d lines and cursor %d", lineCount, r.Cursor.Y)
+	}
+}
+
+func TestFlowPageBreak(t *testing.T) {
+	r := newFlowReport(t)
+	r.SetCursor(1, 55)
+	text := strings.Repeat("line\n", 10)
+	lineCount, err := r.Flow("full", "body", text)
+	if err != nil {
+		t.Fatalf("Flow returned an error: %v", err)
+	}
+	if lineCount != 10 {
+		t.Errorf("Flow returned wrong line count. Got %d expected %d", lineCount, 10)
+	}
+	if r.Pdf.PageNo() != 2 {
+		t.Errorf("Flow did not add a page. Got page %d expected %d", r.Pdf.PageNo(), 2)
+	}
+	// 6 lines fit on the first page (55 to 60), 4 carry over
+	if r.Cursor.Y != 5 {
+		t.Errorf("Flow did not carry text over. Got cursor %d expected %d", r.Cursor.Y, 5)
+	}
+
+	r.SetCursor(1, 60)
+	r.Flow("double", "body", "too tall for the last line")
+	if r.Pdf.PageNo() != 3 || r.Cursor.Y != 3 {
+		t.Errorf("Flow did not break before a block crossing the margin. Got page %d cursor %d", r.Pdf.PageNo(), r.Cursor.Y)
+	}
+}
@@ -0,0 +1,227 @@
+package tps
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// compileLocks holds a *sync.Mutex for each compiled file so reports in the
+// same process never compile the same font or encoding at once.
+var compileLocks sync.Map
+
+// lockCompiled locks the compiled file and returns the unlock function.
+func lockCompiled(filename string) (unlock func()) {
+	if abs, err := filepath.Abs(filename); err == nil {
+		filename = abs
+	}
+	lock, _ := compileLocks.LoadOrStore(filename, new(sync.Mutex))
+	mutex := lock.(*sync.Mutex)
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// fontCacheKey names the compiled files of a font after its family, encoding
+// and a hash of its source, so a changed source font is compiled again
+// instead of reusing a stale file.
+func fontCacheKey(filename, encoding string, source []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, encoding+"\x00")
+	hash.Write(source)
+	family := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	return family + "-" + encoding + "-" + hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// writeAtomic writes a file with write, first to a temporary file in the same
+// directory which is then renamed, so other processes never read a partly
+// written file. A replaced file keeps its permissions, and a new file gets
+// those of os.Create(), 0666 less the umask.
+func writeAtomic(filename string, write func(io.Writer) error) error {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		// the temporary file must be on the same filesystem to be renamed
+		dir = "."
+	}
+	mode := os.FileMode(0666)
+	existing, err := os.Stat(filename)
+	if err == nil {
+		mode = existing.Mode().Perm()
+	}
+	file, err := createTemp(dir, base, mode)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err = write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if existing != nil {
+		// the umask may have removed permissions the replaced file had
+		if err = os.Chmod(file.Name(), mode); err != nil {
+			return err
+		}
+	}
+	return os.Rename(file.Name(), filename)
+}
+
+// createTemp creates a new hidden file in dir named after base. Unlike
+// ioutil.TempFile(), which always uses 0600, the permissions are mode less
+// the umask.
+func createTemp(dir, base string, mode os.FileMode) (*os.File, error) {
+	for try := 0; ; try++ {
+		name := filepath.Join(dir, "."+base+"."+strconv.FormatUint(uint64(rand.Uint32()), 10)+".tmp")
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, mode)
+		if os.IsExist(err) && try < 100 {
+			continue
+		}
+		return file, err
+	}
+}
+
+// compileEncoding creates the encoding map file in dir unless it exists.
+func compileEncoding(dir, encoding string) (filename string, err error) {
+	filename = path.Join(dir, encoding+".map")
+	data, ok := encodings[encoding]
+	if !ok {
+		return filename, fmt.Errorf("Encoding not supported: %s", encoding)
+	}
+	defer lockCompiled(filename)()
+	if _, err = os.Stat(filename); err == nil {
+		return filename, nil
+	}
+	err = writeAtomic(filename, func(w io.Writer) error {
+		decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(data))
+		_, err := io.Copy(w, decoder)
+		return err
+	})
+	if err != nil {
+		err = fmt.Errorf("Could not compile encoding file: %v", err)
+	}
+	return filename, err
+}
+
+// compileFont compiles the font source into key.json and key.z in dir unless
+// they exist.
+func compileFont(dir, key, filename, encoding string, source []byte) error {
+	encodingFilename, err := compileEncoding(dir, encoding)
+	if err != nil {
+		return err
+	}
+	compiledFilename := key + ".json"
+	defer lockCompiled(path.Join(dir, compiledFilename))()
+	if _, err = os.Stat(path.Join(dir, compiledFilename)); err == nil {
+		return nil
+	}
+	// MakeFont names its files after the font file, so it compiles a copy named
+	// after the key in a temporary directory before the files are moved in
+	tmp, err := ioutil.TempDir(dir, ".compile-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	fontFilename := path.Join(tmp, key+path.Ext(filename))
+	if err = ioutil.WriteFile(fontFilename, source, 0644); err != nil {
+		return err
+	}
+	if err = gofpdf.MakeFont(fontFilename, encodingFilename, tmp, nil, true); err != nil {
+		return err
+	}
+	// the .json refers to the .z file so the .z file is moved first
+	for _, name := range []string{key + ".z", compiledFilename} {
+		compiled := path.Join(tmp, name)
+		if _, err = os.Stat(compiled); os.IsNotExist(err) {
+			continue
+		}
+		if err = os.Chmod(compiled, 0644); err != nil {
+			return err
+		}
+		if err = os.Rename(compiled, path.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// memoryFonts holds the fonts compiled without a cache directory by cache key,
+// so each is only compiled once per process.
+var memoryFonts sync.Map
+
+type memoryFont struct {
+	once       sync.Once
+	definition []byte
+	data       []byte
+	err        error
+}
+
+// compileInMemory compiles the font source and returns its .json definition
+// and .z data, which are kept in memory. gofpdf.MakeFont() only works with
+// files, so the compiling is done in a temporary directory under os.TempDir()
+// that is removed right away. Without a writable temporary directory the
+// error says to set a font cache directory instead.
+func compileInMemory(filename, encoding string, source []byte) (definition, data []byte, err error) {
+	key := fontCacheKey(filename, encoding, source)
+	value, _ := memoryFonts.LoadOrStore(key, new(memoryFont))
+	font := value.(*memoryFont)
+	font.once.Do(func() {
+		dir, err := ioutil.TempDir("", "tps-font-")
+		if err != nil {
+			font.err = fmt.Errorf(
+				"compiling needs a writable temporary directory, or a font cache directory: %v",
+				err,
+			)
+			return
+		}
+		defer os.RemoveAll(dir)
+		if font.err = compileFont(dir, key, filename, encoding, source); font.err != nil {
+			return
+		}
+		font.definition, font.data, font.err = readFontDefinition(func(name string) ([]byte, error) {
+			return ioutil.ReadFile(path.Join(dir, name))
+		}, key+".json")
+	})
+	if font.err != nil {
+		// compile again next time in case the error does not last
+		memoryFonts.Delete(key)
+	}
+	return font.definition, font.data, font.err
+}
+
+// readFontDefinition reads the .json definition of a compiled font and the .z
+// file next to it that it embeds, if any, for Fpdf.AddFontFromBytes().
+func readFontDefinition(
+	read func(filename string) ([]byte, error),
+	filename string,
+) (definition, data []byte, err error) {
+	definition, err = read(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Could not read compiled font: %v", err)
+	}
+	var font struct{ File string }
+	if err = json.Unmarshal(definition, &font); err != nil {
+		return nil, nil, fmt.Errorf("Could not parse compiled font %s: %v", filename, err)
+	}
+	if font.File != "" {
+		data, err = read(path.Join(path.Dir(filename), font.File))
+		if err != nil {
+			return nil, nil, fmt.Errorf("Could not read compiled font: %v", err)
+		}
+	}
+	return definition, data, nil
+}
@@ -0,0 +1,76 @@
+package tps
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestCompileFontConcurrently(t *testing.T) {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "Go.ttf"), goregular.TTF, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	names := make([]string, 8)
+	errs := make([]error, 8)
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r, _ := NewReport(WithFontPath(dir))
+			names[i], errs[i] = r.CompileFont("Go.ttf", "cp1252")
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("CompileFont returned an error: %v", err)
+		}
+		if names[i] != names[0] {
+			t.Errorf("CompileFont returned different names. Got %s expected %s", names[i], names[0])
+		}
+	}
+	if !strings.HasPrefix(names[0], "Go-cp1252-") {
+		t.Errorf("CompileFont returned the wrong name. Got %s", names[0])
+	}
+
+	compiled := filepath.Join(dir, "_compiled")
+	data, err := ioutil.ReadFile(filepath.Join(compiled, names[0]))
+	var definition struct{ File string }
+	if err != nil || json.Unmarshal(data, &definition) != nil {
+		t.Fatalf("CompileFont did not write a complete .json file: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(compiled, ".*"))
+	if len(files) > 0 {
+		t.Errorf("CompileFont left temporary files: %v", files)
+	}
+
+	// a changed font file is compiled again
+	err = ioutil.WriteFile(filepath.Join(dir, "Go.ttf"), gobold.TTF, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _ := NewReport(
+		WithFontPath(dir),
+		WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36.0, 12, 12.0, 12.0),
+	)
+	changed, err := r.CompileFont("Go.ttf", "cp1252")
+	if err != nil || changed == names[0] {
+		t.Errorf("CompileFont reused the stale font. Got %s error %v", changed, err)
+	}
+	if err = r.AddFont("Go.ttf", "cp1252"); err != nil {
+		t.Errorf("AddFont returned an error: %v", err)
+	}
+	if err = r.Pdf.Error(); err != nil {
+		t.Errorf("AddFont did not add the compiled font: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package tps
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"path"
+)
+
+// SetFontFS tells the Report to load fonts specified with AddFont() and
+// AddUTF8Font() from fsys, such as an embed.FS, instead of a directory:
+//
+//	//go:embed fonts
+//	var fonts embed.FS
+//
+//	sub, _ := fs.Sub(fonts, "fonts")
+//	r.SetFontFS(sub)
+//	r.AddFont("OpenSans-Bold.ttf", "cp1252")
+//
+// Fonts are compiled once per process and kept in memory unless
+// SetFontCacheDir() sets a directory to keep them in. Nothing is written to
+// fsys so it can be read only, but compiling for AddFont() briefly needs a
+// writable os.TempDir() when there is no cache directory. AddUTF8Font() and
+// already compiled .json fonts need no writable directory at all.
+func (r *Report) SetFontFS(fsys fs.FS) {
+	r.fontFS = fsys
+	r.FontSourcePath = ""
+	r.FontCompiledPath = ""
+	if r.Pdf != nil {
+		r.Pdf.SetFontLocation("")
+	}
+}
+
+// SetFontCacheDir sets the directory compiled fonts are kept in, replacing the
+// "_compiled" subdirectory of SetFontPath(). An empty dir compiles fonts in
+// memory instead. It must be called after SetFontPath() or SetFontFS(), which
+// reset it, and applies to the fonts added after it. WithFontCacheDir() sets
+// it when creating the Report.
+func (r *Report) SetFontCacheDir(dir string) {
+	r.FontCompiledPath = dir
+	if r.Pdf != nil {
+		r.Pdf.SetFontLocation(dir)
+	}
+}
+
+// readFontSource reads a font file from the fs.FS of SetFontFS() or
+// Report.FontSourcePath.
+func (r *Report) readFontSource(filename string) ([]byte, error) {
+	if r.fontFS != nil {
+		return fs.ReadFile(r.fontFS, filename)
+	}
+	return ioutil.ReadFile(path.Join(r.FontSourcePath, filename))
+}
+
+// loadFont returns the .json definition and .z data of a font for AddFont(),
+// compiling a source font with the encoding first.
+func (r *Report) loadFont(filename, encoding string) (definition, data []byte, err error) {
+	if path.Ext(filename) == ".json" {
+		if r.fontFS != nil {
+			return readFontDefinition(func(name string) ([]byte, error) {
+				return fs.ReadFile(r.fontFS, name)
+			}, filename)
+		}
+		if !r.IsCompiledFile(filename) {
+			return nil, nil, fmt.Errorf("Cache font file not found: %s", filename)
+		}
+		return readFontDefinition(func(name string) ([]byte, error) {
+			return ioutil.ReadFile(path.Join(r.FontCompiledPath, name))
+		}, filename)
+	}
+
+	if !r.IsSourcedFont(filename) {
+		return nil, nil, fmt.Errorf("Source font file not found: %s", filename)
+	}
+	if r.FontCompiledPath == "" {
+		source, err := r.readFontSource(filename)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Could not read font file: %v", err)
+		}
+		definition, data, err = compileInMemory(filename, encoding, source)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Could not compile font: %v", err)
+		}
+		return definition, data, nil
+	}
+	compiled, err := r.CompileFont(filename, encoding)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Could not compile font: %v", err)
+	}
+	return readFontDefinition(func(name string) ([]byte, error) {
+		return ioutil.ReadFile(path.Join(r.FontCompiledPath, name))
+	}, compiled)
+}
@@ -0,0 +1,88 @@
+package tps
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newFontFSReport(t *testing.T) *Report {
+	fonts := fstest.MapFS{"fonts/Go.ttf": {Data: goregular.TTF}}
+	r, err := NewReport(
+		WithFontFS(fonts),
+		WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36.0, 12, 12.0, 12.0),
+		WithStyles(map[string]Style{"body": {FontFamily: "Go", FontSize: 10, Alignment: AlignLeft}}),
+		WithBlocks(map[string]Block{"full": {12, 1}}),
+	)
+	if err != nil {
+		t.Fatalf("NewReport returned an error: %v", err)
+	}
+	return r
+}
+
+func TestSetFontFS(t *testing.T) {
+	r := newFontFSReport(t)
+	if err := r.AddFont("fonts/Go.ttf", "cp1252"); err != nil {
+		t.Fatalf("AddFont returned an error for a font in an FS: %v", err)
+	}
+	if r.FontCompiledPath != "" {
+		t.Errorf("SetFontFS did not compile fonts in memory. Got %s", r.FontCompiledPath)
+	}
+	if err := r.AddUTF8Font("GoUTF8", "", "fonts/Go.ttf"); err != nil {
+		t.Errorf("AddUTF8Font returned an error for a font in an FS: %v", err)
+	}
+	if _, err := r.Flow("full", "body", "Hello"); err != nil {
+		t.Errorf("Flow returned an error with a font from an FS: %v", err)
+	}
+	if _, err := r.Bytes(); err != nil {
+		t.Errorf("Bytes returned an error with a font from an FS: %v", err)
+	}
+	if err := r.AddFont("fonts/Missing.ttf", "cp1252"); err == nil {
+		t.Errorf("AddFont did not return an error for a missing font")
+	}
+}
+
+func TestSetFontCacheDir(t *testing.T) {
+	r := newFontFSReport(t)
+	dir := t.TempDir()
+	r.SetFontCacheDir(dir)
+	if err := r.AddFont("fonts/Go.ttf", "cp1252"); err != nil {
+		t.Fatalf("AddFont returned an error with a cache dir: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "Go-cp1252-*.json"))
+	if len(files) != 1 {
+		t.Errorf("AddFont did not compile the font into the cache dir. Got %v", files)
+	}
+}
+
+func TestWithFontFSAndPath(t *testing.T) {
+	_, err := NewReport(WithFontFS(fstest.MapFS{}), WithFontPath(t.TempDir()))
+	if err == nil || !strings.Contains(err.Error(), "not both") {
+		t.Errorf("NewReport did not reject a font path and FS together. Got %v", err)
+	}
+}
+
+func TestWithFontCacheDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	r, err := NewReport(
+		WithFontFS(fstest.MapFS{"Go.ttf": {Data: goregular.TTF}}),
+		WithFontCacheDir(dir),
+		WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36.0, 12, 12.0, 12.0),
+	)
+	if err != nil {
+		t.Fatalf("NewReport returned an error: %v", err)
+	}
+	if err = r.AddFont("Go.ttf", "cp1252"); err != nil {
+		t.Fatalf("AddFont returned an error: %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "Go-cp1252-*.json")); len(files) != 1 {
+		t.Errorf("WithFontCacheDir did not keep the compiled font. Got %v", files)
+	}
+
+	if _, err = NewReport(WithFontCacheDir(dir)); err == nil {
+		t.Error("NewReport did not reject a font cache directory without fonts")
+	}
+}
@@ -0,0 +1,98 @@
+package tps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Frame is a rectangular region of the grid that FlowInto() fills with text,
+// such as a column of a newsletter. X and Y are the grid coordinates of the
+// top left like Report.Content(), Width is the # of columns and Height the #
+// of lines. When NewPage is true a page is added before the frame is filled,
+// so a chain of frames can continue onto the next page.
+type Frame struct {
+	X, Y          int
+	Width, Height int
+	NewPage       bool
+}
+
+// FlowInto wraps text through the frames in order with the named style, one
+// grid line per wrapped line, filling each frame before continuing in the
+// next. Text is rewrapped for the width of every frame. For example, an
+// article in two columns that continues on the next page:
+//
+//   leftover, err := r.FlowInto([]Frame{
+//   	{X: 1, Y: 5, Width: 6, Height: 40},
+//   	{X: 7, Y: 5, Width: 6, Height: 40},
+//   	{X: 1, Y: 1, Width: 12, Height: 60, NewPage: true},
+//   }, "body", article)
+//
+// Returns the text left over when the frames are full, which is empty when
+// all of it is placed. Frames are not filled once the text runs out.
+func (r *Report) FlowInto(
+	frames []Frame,
+	styleName string,
+	text string,
+	overrides ...StyleOption,
+) (leftover string, err error) {
+	style, err := r.Style(styleName, overrides...)
+	if err != nil {
+		return text, err
+	}
+	if err = r.checkGlyphs(style, text); err != nil {
+		return text, err
+	}
+	for i, frame := range frames {
+		if err = r.checkFrame(frame); err != nil {
+			return text, fmt.Errorf("Frame %d %v", i+1, err)
+		}
+	}
+
+	for i, frame := range frames {
+		if text == "" {
+			break
+		}
+		if frame.NewPage && r.Pdf.PageNo() > 0 {
+			r.AddPage()
+		}
+		r.startPage()
+		block := Block{Width: frame.Width, Height: 1}
+		lines, err := r.wrap(block, style, text)
+		if err != nil {
+			return text, err
+		}
+		if len(lines) > frame.Height {
+			lines = lines[:frame.Height]
+		}
+		r.place(frame.X, frame.Y, block, style, strings.Join(lines, "\n"), "")
+		r.outline(frame.X, frame.Y, block, frame.Height, fmt.Sprintf("frame %d", i+1))
+		text = after(text, lines)
+	}
+	return text, nil
+}
+
+// checkFrame makes sure the frame is inside the grid.
+func (r *Report) checkFrame(frame Frame) error {
+	if frame.X < 1 || frame.Y < 1 || frame.Width < 1 || frame.Height < 1 {
+		return fmt.Errorf("position and size must be positive: %+v", frame)
+	}
+	if frame.X-1+frame.Width > r.Grid.ColumnCount || frame.Y-1+frame.Height > r.Grid.LineCount() {
+		return fmt.Errorf("does not fit in the grid: %+v", frame)
+	}
+	return nil
+}
+
+// after returns the text that follows the wrapped lines taken from the start
+// of it, without the whitespace the lines were broken at.
+func after(text string, lines []string) string {
+	pos := 0
+	for _, line := range lines {
+		pos += len(text[pos:]) - len(strings.TrimLeft(text[pos:], " \t\r\n"))
+		if strings.HasPrefix(text[pos:], line) {
+			pos += len(line)
+		} else if i := strings.Index(text[pos:], line); i >= 0 {
+			pos += i + len(line)
+		}
+	}
+	return strings.TrimLeft(text[pos:], " \t\r\n")
+}
@@ -0,0 +1,72 @@
+package tps
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFlowInto(t *testing.T) {
+	r := newFlowReport(t)
+	words := make([]string, 200)
+	for i := range words {
+		words[i] = "word"
+	}
+	text := strings.Join(words, " ")
+
+	frames := []Frame{
+		{X: 1, Y: 1, Width: 6, Height: 3},
+		{X: 7, Y: 1, Width: 6, Height: 3},
+	}
+	leftover, err := r.FlowInto(frames, "body", text)
+	if err != nil {
+		t.Fatalf("FlowInto returned an error: %v", err)
+	}
+	if leftover == "" || !strings.HasPrefix(leftover, "word") || !strings.HasSuffix(text, leftover) {
+		t.Fatalf("FlowInto did not return the leftover text. Got %q", leftover)
+	}
+	placed := strings.Count(text, "word") - strings.Count(leftover, "word")
+	m, _ := r.Measure("full", "body", strings.Repeat("word ", placed))
+	if m.LineCount < 2 || m.LineCount > 4 {
+		t.Errorf("FlowInto placed the wrong amount of text. Got %d words", placed)
+	}
+
+	// the leftover continues on the next page
+	leftover, err = r.FlowInto([]Frame{{X: 1, Y: 1, Width: 12, Height: 60, NewPage: true}}, "body", leftover)
+	if err != nil || leftover != "" {
+		t.Errorf("FlowInto did not place all of the text. Got %q error %v", leftover, err)
+	}
+	if r.Pdf.PageNo() != 2 {
+		t.Errorf("FlowInto did not add a page for the frame. Got page %d", r.Pdf.PageNo())
+	}
+
+	// frames after the text runs out are not filled
+	if _, err = r.FlowInto([]Frame{frames[0], {X: 1, Y: 1, Width: 1, Height: 1, NewPage: true}}, "body", "short"); err != nil {
+		t.Fatalf("FlowInto returned an error: %v", err)
+	}
+	if r.Pdf.PageNo() != 2 {
+		t.Errorf("FlowInto added a page for an unused frame")
+	}
+}
+
+func TestFlowIntoErrors(t *testing.T) {
+	r := newFlowReport(t)
+	if _, err := r.FlowInto([]Frame{{X: 7, Y: 1, Width: 7, Height: 1}}, "body", "text"); err == nil {
+		t.Errorf("FlowInto did not return an error for a frame outside the grid")
+	}
+	if _, err := r.FlowInto([]Frame{{X: 1, Y: 1, Width: 1, Height: 0}}, "body", "text"); err == nil {
+		t.Errorf("FlowInto did not return an error for an empty frame")
+	}
+	if leftover, err := r.FlowInto(nil, "missing", "text"); err == nil || leftover != "text" {
+		t.Errorf("FlowInto did not return an error for a missing style")
+	}
+}
+
+func TestAfter(t *testing.T) {
+	text := "one two\n\nthree four five"
+	if got := after(text, []string{"one two", "", "three"}); got != "four five" {
+		t.Errorf("after returned the wrong text. Got %q expected %q", got, "four five")
+	}
+	if got := after(text, []string{"one two", "three four five"}); got != "" {
+		t.Errorf("after returned text after the last line. Got %q", got)
+	}
+}
@@ -2,10 +2,19 @@ package tps
 
 import (
 	"errors"
+	"fmt"
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
 )
 
 // Grid holds all the page and grid specification required for the Report to
-// create new pages and place content.
+// create new pages and place content. CustomWidth and CustomHeight are the
+// portrait page width and height in Unit used when PageSize is
+// PageSizeCustom, such as 4 x 6 for a shipping label in UnitIn. Margins
+// replace Margin on every side when any of them is set, and Page is the page
+// # GetPoint() places on, which matters for mirrored margins. BaselineGrid
+// and BaselineOffset are set by Report.SetBaselineGrid().
 type Grid struct {
 	ColumnCount int
 	ColumnWidth float64
@@ -18,6 +27,25 @@ type Grid struct {
 	PageHeight  float64
 	PageSize    int
 	Unit        int
+
+	CustomWidth  float64
+	CustomHeight float64
+	Margins      Margins
+	Page         int
+
+	BaselineGrid   bool
+	BaselineOffset float64
+}
+
+// Margins are the space between each page edge and the grid. When Mirrored,
+// Left is the inner margin next to the binding and Right is the outer margin,
+// so they swap sides on even pages for facing pages of a bound booklet.
+type Margins struct {
+	Top      float64
+	Right    float64
+	Bottom   float64
+	Left     float64
+	Mirrored bool
 }
 
 // Point is the X, Y coordinates in the Grid.Unit system relative to the PDF's
@@ -39,8 +67,9 @@ func (g *Grid) CalculateColumns() error {
 		return errors.New("Incomplete data to calculate grid columns")
 	}
 	g.GutterCount = g.ColumnCount - 1
+	_, right, _, left := g.pageMargins()
 	width := g.PageWidth
-	width -= g.Margin * 2
+	width -= left + right
 	width -= float64(g.GutterCount) * g.GutterWidth
 	g.ColumnWidth = width / float64(g.ColumnCount)
 	return nil
@@ -58,20 +87,112 @@ func (g *Grid) GetCell(block Block) Cell {
 	return cell
 }
 
-// GetPoint returns a Point struct for use in lower level Fpdf calls
+// fit sets the page width and height for the page size and orientation,
+// recalculates the columns and checks at least one column and one line fit
+// between the margins.
+func (g *Grid) fit() error {
+	size := g.pageDimensions()
+	g.PageWidth, g.PageHeight = size.Wd, size.Ht
+	if g.Orientation == OrientationLandscape {
+		g.PageWidth, g.PageHeight = size.Ht, size.Wd
+	}
+	if err := g.CalculateColumns(); err != nil {
+		return err
+	}
+	if g.ColumnWidth <= 0 || g.LineCount() == 0 {
+		return errors.New("Grid does not fit between the margins of the page")
+	}
+	return nil
+}
+
+// LineCount returns the # of lines that fit on a page between the top and
+// bottom margins.
+func (g *Grid) LineCount() int {
+	if g.LineHeight <= 0 {
+		return 0
+	}
+	top, _, bottom, _ := g.pageMargins()
+	height := g.PageHeight - top - bottom
+	// small tolerance so exact fits are not lost to float rounding
+	return int(math.Floor(height/g.LineHeight + 1e-9))
+}
+
+// pageMargins returns the margins of Grid.Page, with mirrored margins swapped
+// on even pages.
+func (g *Grid) pageMargins() (top, right, bottom, left float64) {
+	m := g.Margins
+	if m.Top == 0 && m.Right == 0 && m.Bottom == 0 && m.Left == 0 {
+		return g.Margin, g.Margin, g.Margin, g.Margin
+	}
+	if m.Mirrored && g.Page > 0 && g.Page%2 == 0 {
+		return m.Top, m.Left, m.Bottom, m.Right
+	}
+	return m.Top, m.Right, m.Bottom, m.Left
+}
+
+// GetPoint returns a Point struct for use in lower level Fpdf calls. The
+// margins are those of Grid.Page.
 func (g *Grid) GetPoint(x, y int) Point {
 	point := Point{}
+	top, _, _, left := g.pageMargins()
 
-	point.X = g.Margin
+	point.X = left
 	point.X += g.ColumnWidth * float64(x-1)
 	point.X += g.GutterWidth * float64(x-1)
 
-	point.Y = g.Margin
+	point.Y = top
 	point.Y += g.LineHeight * float64(y-1)
 
 	return point
 }
 
+// validate checks the specs given to SetGrid are usable before anything is
+// calculated from them.
+func (g *Grid) validate() error {
+	if g.convertOrientation() == "" {
+		return fmt.Errorf("Invalid grid orientation: %d", g.Orientation)
+	}
+	if g.convertPageSize() == "" {
+		return fmt.Errorf("Invalid grid page size: %d", g.PageSize)
+	}
+	if g.convertUnit() == "" {
+		return fmt.Errorf("Invalid grid unit: %d", g.Unit)
+	}
+	if g.ColumnCount <= 0 || g.GutterWidth <= 0 || g.LineHeight <= 0 {
+		return errors.New("Grid column count, gutter width and line height must be positive")
+	}
+	if g.Margin < 0 {
+		return fmt.Errorf("Grid margin cannot be negative: %.2f", g.Margin)
+	}
+	return g.Margins.validate()
+}
+
+// validate checks no margin is negative.
+func (m Margins) validate() error {
+	if m.Top < 0 || m.Right < 0 || m.Bottom < 0 || m.Left < 0 {
+		return fmt.Errorf("Grid margins cannot be negative: %+v", m)
+	}
+	return nil
+}
+
+// validatePageDimensions checks a custom page size has its dimensions.
+func (g *Grid) validatePageDimensions() error {
+	if g.PageSize == PageSizeCustom && (g.CustomWidth <= 0 || g.CustomHeight <= 0) {
+		return errors.New("Custom page width and height must be positive")
+	}
+	return nil
+}
+
+// pageDimensions returns the portrait page width and height in Grid.Unit.
+func (g *Grid) pageDimensions() gofpdf.SizeType {
+	if g.PageSize == PageSizeCustom {
+		return gofpdf.SizeType{Wd: g.CustomWidth, Ht: g.CustomHeight}
+	}
+	size := pageDimensions[g.PageSize]
+	points := unitPoints[g.Unit]
+	return gofpdf.SizeType{Wd: size.Wd / points, Ht: size.Ht / points}
+}
+
 func (g *Grid) convertOrientation() string {
 	return orientation[g.Orientation]
 }
@@ -1,6 +1,7 @@
 package tps
 
 import (
+	"math"
 	"testing"
 )
 
@@ -111,3 +112,163 @@ func TestGetPoint(t *testing.T) {
 	}
 
 }
+
+func TestLineCount(t *testing.T) {
+	g := newGrid()
+	if c := g.LineCount(); c != 60 {
+		t.Errorf("Grid did not return correct LineCount. Got %d expected %d", c, 60)
+	}
+}
+
+func TestPageDimensions(t *testing.T) {
+	g := Grid{PageSize: PageSizeExecutive, Unit: UnitIn}
+	size := g.pageDimensions()
+	if math.Abs(size.Wd-7.25) > 1e-9 || math.Abs(size.Ht-10.5) > 1e-9 {
+		t.Errorf("Grid did not convert the page size to the unit. Got %v", size)
+	}
+	g = Grid{PageSize: PageSizeCustom, Unit: UnitIn, CustomWidth: 4, CustomHeight: 6}
+	if size = g.pageDimensions(); size.Wd != 4 || size.Ht != 6 {
+		t.Errorf("Grid did not use the custom page size. Got %v", size)
+	}
+	g.CustomHeight = 0
+	if g.validatePageDimensions() == nil {
+		t.Error("Grid did not reject a custom page size without a height.")
+	}
+}
+
+func TestCustomPageSize(t *testing.T) {
+	r, err := NewReport(
+		WithGrid(OrientationPortrait, PageSizeCustom, UnitMm, 4, 4, 2, 5),
+		WithPageDimensions(80, 200),
+	)
+	if err != nil {
+		t.Fatalf("NewReport returned an error: %v", err)
+	}
+	if r.Grid.PageSize != PageSizeCustom || r.Grid.PageWidth != 80 || r.Grid.PageHeight != 200 {
+		t.Errorf("NewReport did not use the custom page size. Got %+v", r.Grid)
+	}
+	if r.Grid.ColumnWidth != 16.5 {
+		t.Errorf("NewReport did not calculate the columns. Got %g expected %g", r.Grid.ColumnWidth, 16.5)
+	}
+
+	_, err = NewReport(WithGrid(OrientationPortrait, PageSizeCustom, UnitMm, 4, 4, 2, 5))
+	if err == nil {
+		t.Error("NewReport did not reject a custom page size without dimensions.")
+	}
+	_, err = NewReport(
+		WithGrid(OrientationPortrait, PageSizeLetter, UnitMm, 4, 4, 2, 5),
+		WithPageDimensions(80, 200),
+	)
+	if err == nil {
+		t.Error("NewReport did not reject page dimensions with a standard page size.")
+	}
+
+	r, _ = NewReport()
+	if err = r.SetGrid(OrientationPortrait, PageSizeCustom, UnitMm, 4, 4, 2, 5); err == nil {
+		t.Error("SetGrid did not reject a custom page size without dimensions.")
+	}
+	if r.Pdf != nil {
+		t.Error("SetGrid changed the report after an error.")
+	}
+	r.Grid.CustomWidth, r.Grid.CustomHeight = 80, 200
+	if err = r.SetGrid(OrientationPortrait, PageSizeCustom, UnitMm, 4, 4, 2, 5); err != nil {
+		t.Errorf("SetGrid returned an error: %v", err)
+	}
+	if err = r.SetGrid(OrientationPortrait, PageSizeA4, UnitMm, 4, 0, 2, 5); err == nil {
+		t.Error("SetGrid did not reject a grid without columns.")
+	}
+	if err = r.SetGrid(OrientationPortrait, PageSizeA4, 99, 4, 4, 2, 5); err == nil {
+		t.Error("SetGrid did not reject an invalid unit.")
+	}
+	if err = r.SetGrid(-1, PageSizeA4, UnitMm, 4, 4, 2, 5); err == nil {
+		t.Error("SetGrid did not reject an invalid orientation.")
+	}
+	if r.Grid.PageSize != PageSizeCustom || r.Grid.ColumnWidth != 16.5 {
+		t.Errorf("SetGrid changed the report after an error. Got %+v", r.Grid)
+	}
+}
+
+func TestAddPageWith(t *testing.T) {
+	r, _ := NewReport(WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36, 12, 12, 12))
+	r.AddPage()
+	if err := r.AddPageWith(OrientationLandscape, PageSizeTabloid); err != nil {
+		t.Fatalf("AddPageWith returned an error: %v", err)
+	}
+	if r.Grid.PageWidth != 1224 || r.Grid.PageHeight != 792 || r.Grid.LineCount() != 60 {
+		t.Errorf("AddPageWith did not recalculate the grid. Got %+v", r.Grid)
+	}
+	width, height := r.Pdf.GetPageSize()
+	if width != 1224 || height != 792 {
+		t.Errorf("AddPageWith did not change the page size. Got %g x %g", width, height)
+	}
+
+	// later pages keep the page size
+	r.AddPage()
+	if width, _ = r.Pdf.GetPageSize(); width != 1224 || r.Pdf.PageNo() != 3 {
+		t.Errorf("AddPage did not keep the page size. Got width %g", width)
+	}
+
+	if err := r.AddPageWith(OrientationPortrait, PageSizeCustom); err == nil {
+		t.Error("AddPageWith did not reject a custom page size without dimensions.")
+	}
+	if r.Pdf.PageNo() != 3 || r.Grid.PageSize != PageSizeTabloid {
+		t.Error("AddPageWith changed the report after an error.")
+	}
+}
+
+func TestMargins(t *testing.T) {
+	g := newGrid()
+	g.Margins = Margins{Top: 72, Right: 36, Bottom: 36, Left: 60}
+	g.CalculateColumns()
+	if g.ColumnWidth != 32 {
+		t.Errorf("Grid did not calculate ColumnWidth with separate margins. Got %g", g.ColumnWidth)
+	}
+	if g.LineCount() != 57 {
+		t.Errorf("Grid did not calculate LineCount with separate margins. Got %d", g.LineCount())
+	}
+	if point := g.GetPoint(1, 1); point.X != 60 || point.Y != 72 {
+		t.Errorf("Grid did not place from the top left margins. Got %+v", point)
+	}
+
+	g.Margins.Mirrored = true
+	for page, x := range map[int]float64{0: 60, 1: 60, 2: 36, 3: 60} {
+		g.Page = page
+		if point := g.GetPoint(1, 1); point.X != x {
+			t.Errorf("Grid did not mirror the margins on page %d. Got %g expected %g", page, point.X, x)
+		}
+	}
+}
+
+func TestSetMargins(t *testing.T) {
+	r, _ := NewReport(WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36, 12, 12, 12))
+	err := r.SetMargins(Margins{Top: 36, Right: 36, Bottom: 36, Left: 60, Mirrored: true})
+	if err != nil {
+		t.Fatalf("SetMargins returned an error: %v", err)
+	}
+	r.AddPage()
+	r.AddPage()
+	if r.Grid.Page != 2 || r.Grid.GetPoint(1, 1).X != 36 {
+		t.Errorf("AddPage did not mirror the margins on an even page. Got %+v", r.Grid)
+	}
+	if left, _, right, _ := r.Pdf.GetMargins(); left != 36 || right != 60 {
+		t.Errorf("AddPage did not set the PDF margins. Got %g and %g", left, right)
+	}
+
+	if err = r.SetMargins(Margins{Left: -1}); err == nil {
+		t.Error("SetMargins did not reject a negative margin.")
+	}
+	if err = r.SetMargins(Margins{Left: 400, Right: 400}); err == nil {
+		t.Error("SetMargins did not reject margins wider than the page.")
+	}
+	_, err = NewReport(
+		WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36, 12, 12, 12),
+		WithMargins(Margins{Left: 400, Right: 400}),
+	)
+	if err == nil {
+		t.Error("NewReport did not reject margins wider than the page.")
+	}
+	_, err = NewReport(WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 400, 12, 12, 12))
+	if err == nil {
+		t.Error("NewReport did not reject a margin taller than the page.")
+	}
+}
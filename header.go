@@ -0,0 +1,83 @@
+package tps
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PageContent is content placed on every page by SetHeader() and SetFooter(),
+// the same as the arguments to Report.Content(). Text can contain the tokens
+// {page} for the current page number and {pages} for the total page count,
+// which is resolved when the report is written out.
+//
+// Headers and footers are usually placed in the margins so they do not collide
+// with Flow(). Line 0 is the line just above the top margin, and the line after
+// Grid.LineCount() is the line just below the bottom margin.
+type PageContent struct {
+	X     int
+	Y     int
+	Block string
+	Style string
+	Text  string
+}
+
+const (
+	tokenPage  = "{page}"
+	tokenPages = "{pages}"
+)
+
+// SetHeader sets the content placed on every page added after this call,
+// replacing any previous header. Calling it between pages switches the header
+// for the following section, and calling it with no content removes it.
+func (r *Report) SetHeader(content ...PageContent) error {
+	if err := r.checkPageContent(content); err != nil {
+		return err
+	}
+	r.header = content
+	return nil
+}
+
+// SetFooter is the same as SetHeader() for the footer.
+func (r *Report) SetFooter(content ...PageContent) error {
+	if err := r.checkPageContent(content); err != nil {
+		return err
+	}
+	r.footer = content
+	return nil
+}
+
+func (r *Report) checkPageContent(content []PageContent) error {
+	for _, c := range content {
+		_, style, err := r.lookup(c.Block, c.Style)
+		if err != nil {
+			return err
+		}
+		if err = r.checkGlyphs(style, c.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decoratePage places the header and footer on the page just added.
+func (r *Report) decoratePage() {
+	page := r.Pdf.PageNo()
+	if page != 1 || !r.SkipFirstHeader {
+		r.placePageContent(r.header, page)
+	}
+	if page != 1 || !r.SkipFirstFooter {
+		r.placePageContent(r.footer, page)
+	}
+}
+
+func (r *Report) placePageContent(content []PageContent, page int) {
+	for _, c := range content {
+		block, style, err := r.lookup(c.Block, c.Style)
+		if err != nil {
+			// the style or block was removed after SetHeader or SetFooter
+			continue
+		}
+		text := strings.Replace(c.Text, tokenPage, strconv.Itoa(page), -1)
+		r.place(c.X, c.Y, block, style, text, c.Block)
+	}
+}
@@ -0,0 +1,49 @@
+package tps
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHeaderFooter(t *testing.T) {
+	r := newFlowReport(t)
+	r.Pdf.SetCompression(false)
+	r.SkipFirstHeader = true
+	err := r.SetHeader(PageContent{1, 0, "full", "body", "Header {page}"})
+	if err != nil {
+		t.Fatalf("SetHeader returned an error: %v", err)
+	}
+	err = r.SetFooter(PageContent{1, 61, "full", "body", "Page {page} of {pages}"})
+	if err != nil {
+		t.Fatalf("SetFooter returned an error: %v", err)
+	}
+	r.AddPage()
+	r.AddPage()
+	b, err := r.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+
+	tests := map[string]bool{
+		"(Header 1)":    false,
+		"(Header 2)":    true,
+		"(Page 1 of 2)": true,
+		"(Page 2 of 2)": true,
+		"{pages}":       false,
+	}
+	for text, expected := range tests {
+		if bytes.Contains(b, []byte(text)) != expected {
+			t.Errorf("Header and footer output wrong. Expected %s present to be %v", text, expected)
+		}
+	}
+}
+
+func TestSetHeaderErrors(t *testing.T) {
+	r := newFlowReport(t)
+	if err := r.SetHeader(PageContent{1, 0, "missing", "body", ""}); err == nil {
+		t.Errorf("SetHeader did not return an error for an unknown block")
+	}
+	if err := r.SetFooter(PageContent{1, 0, "full", "missing", ""}); err == nil {
+		t.Errorf("SetFooter did not return an error for an unknown style")
+	}
+}
@@ -0,0 +1,135 @@
+package tps
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Image places a PNG, JPEG or GIF image based on the x, y coordinates on the
+// grid, sized to the named block. The source is either a filename or an
+// io.Reader. The scale is one of:
+//
+//	ImageFit      scaled to fit inside the block, keeping its proportions
+//	ImageFill     scaled to cover the block, keeping its proportions, and
+//	              clipped to the block
+//	ImageStretch  stretched to the exact size of the block
+//
+// The alignment of the named style positions the image within the block when
+// its proportions differ. The same image used more than once is only embedded
+// in the PDF once.
+func (r *Report) Image(
+	x int,
+	y int,
+	blockName string,
+	styleName string,
+	source interface{},
+	scale int,
+) error {
+	if scale != ImageFit && scale != ImageFill && scale != ImageStretch {
+		return fmt.Errorf("Invalid image scale: %d", scale)
+	}
+	block, style, err := r.lookup(blockName, styleName)
+	if err != nil {
+		return err
+	}
+	data, err := readImage(source)
+	if err != nil {
+		return err
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("Could not read image: %v", err)
+	}
+	if config.Width == 0 || config.Height == 0 {
+		return errors.New("Image has no size")
+	}
+
+	sum := sha1.Sum(data)
+	name := hex.EncodeToString(sum[:])
+	options := gofpdf.ImageOptions{ImageType: format}
+	if err = r.Pdf.Error(); err != nil {
+		return err
+	}
+	r.Pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(data))
+	if err = r.Pdf.Error(); err != nil {
+		// Pdf rejects some images that decode, such as 16-bit PNGs. The image
+		// is not registered, so the error is cleared to keep the document
+		// usable.
+		r.Pdf.ClearError()
+		return fmt.Errorf("Could not add image: %v", err)
+	}
+
+	r.startPage()
+	point := r.Grid.GetPoint(x, y)
+	cell := r.Grid.GetCell(block)
+	width, height := cell.Width, cell.Height
+	ratio := float64(config.Width) / float64(config.Height)
+	switch scale {
+	case ImageFit:
+		if width/height > ratio {
+			width = height * ratio
+		} else {
+			height = width / ratio
+		}
+	case ImageFill:
+		if width/height > ratio {
+			height = width / ratio
+		} else {
+			width = height * ratio
+		}
+	}
+
+	imageX, imageY := point.X, point.Y
+	switch {
+	case style.Alignment&AlignCenter > 0:
+		imageX += (cell.Width - width) / 2
+	case style.Alignment&AlignRight > 0:
+		imageX += cell.Width - width
+	}
+	switch {
+	case style.Alignment&AlignMiddle > 0:
+		imageY += (cell.Height - height) / 2
+	case style.Alignment&AlignBottom > 0:
+		imageY += cell.Height - height
+	}
+
+	if scale == ImageFill {
+		r.Pdf.ClipRect(point.X, point.Y, cell.Width, cell.Height, false)
+	}
+	r.Pdf.ImageOptions(name, imageX, imageY, width, height, false, options, 0, "")
+	if scale == ImageFill {
+		r.Pdf.ClipEnd()
+	}
+	r.outline(x, y, block, 1, blockName)
+	return r.Pdf.Error()
+}
+
+// readImage reads all image data from a filename or io.Reader source.
+func readImage(source interface{}) ([]byte, error) {
+	switch s := source.(type) {
+	case string:
+		data, err := ioutil.ReadFile(s)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read image file: %v", err)
+		}
+		return data, nil
+	case io.Reader:
+		data, err := ioutil.ReadAll(s)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read image: %v", err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("Image source must be a filename or io.Reader, got %T", source)
+}
@@ -0,0 +1,80 @@
+package tps
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for x := 0; x < 40; x++ {
+		img.Set(x, 10, color.RGBA{255, 0, 0, 255})
+	}
+	return img
+}
+
+func TestImage(t *testing.T) {
+	r := newFlowReport(t)
+	r.AddStyle("centered", "Helvetica", "", 10, AlignCenter|AlignMiddle)
+	r.AddBlock("logo", 4, 4)
+
+	var pngData bytes.Buffer
+	png.Encode(&pngData, newTestImage())
+	filename := filepath.Join(t.TempDir(), "logo.png")
+	if err := ioutil.WriteFile(filename, pngData.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var jpegData bytes.Buffer
+	jpeg.Encode(&jpegData, newTestImage(), nil)
+
+	tests := []struct {
+		source interface{}
+		scale  int
+	}{
+		{filename, ImageFit},
+		{bytes.NewReader(pngData.Bytes()), ImageFill},
+		{&jpegData, ImageStretch},
+	}
+	for _, test := range tests {
+		if err := r.Image(1, 1, "logo", "centered", test.source, test.scale); err != nil {
+			t.Errorf("Image returned an error: %v", err)
+		}
+	}
+	if _, err := r.Bytes(); err != nil {
+		t.Errorf("Bytes returned an error with images: %v", err)
+	}
+}
+
+func TestImageErrors(t *testing.T) {
+	r := newFlowReport(t)
+	// 16-bit PNGs decode but Pdf does not support them
+	var deep bytes.Buffer
+	if err := png.Encode(&deep, image.NewRGBA64(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]error{
+		"16-bit png":   r.Image(1, 1, "full", "body", &deep, ImageFit),
+		"not an image": r.Image(1, 1, "full", "body", strings.NewReader("text"), ImageFit),
+		"bad source":   r.Image(1, 1, "full", "body", 42, ImageFit),
+		"bad scale":    r.Image(1, 1, "full", "body", "logo.png", -1),
+		"missing file": r.Image(1, 1, "full", "body", "does-not-exist.png", ImageFit),
+	}
+	for name, err := range tests {
+		if err == nil {
+			t.Errorf("Image did not return an error for %s", name)
+		}
+	}
+	if err := r.Pdf.Error(); err != nil {
+		t.Errorf("Image errors were left on the Pdf: %v", err)
+	}
+	if _, err := r.Flow("full", "body", "Total"); err != nil {
+		t.Errorf("Flow returned an error after an image error: %v", err)
+	}
+}
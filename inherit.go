@@ -0,0 +1,87 @@
+package tps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// derivedStyle is a style added with AddStyleFrom(), resolved from its parent
+// whenever it is used.
+type derivedStyle struct {
+	parent    string
+	overrides []StyleOption
+}
+
+// AddStyleFrom adds a new style that extends the parent style with overrides.
+// The parent is resolved every time the style is used, so changes to it
+// cascade to this style. For example:
+//
+//	r.AddStyle("body", "OpenSans", "", 10, AlignLeft|AlignTop)
+//	r.AddStyleFrom("total", "body", StyleBold(), StyleAlignment(AlignRight))
+//
+// Returns an error when the parent does not exist or the style would inherit
+// from itself.
+func (r *Report) AddStyleFrom(name, parent string, overrides ...StyleOption) error {
+	previous, wasStyle := r.Styles[name]
+	previousDerived, wasDerived := r.derivedStyles[name]
+
+	delete(r.Styles, name)
+	r.derivedStyles[name] = derivedStyle{parent: parent, overrides: overrides}
+	if _, err := r.Style(name); err != nil {
+		delete(r.derivedStyles, name)
+		if wasStyle {
+			r.Styles[name] = previous
+		}
+		if wasDerived {
+			r.derivedStyles[name] = previousDerived
+		}
+		return err
+	}
+	return nil
+}
+
+// Style returns the named style with its parents resolved and the overrides
+// applied.
+func (r *Report) Style(name string, overrides ...StyleOption) (Style, error) {
+	style, err := r.resolveStyle(name, nil)
+	if err != nil {
+		return style, err
+	}
+	for _, override := range overrides {
+		override(&style)
+	}
+	return style, nil
+}
+
+// resolveStyle follows the parents of a derived style, with chain holding the
+// names already followed to find cycles.
+func (r *Report) resolveStyle(name string, chain []string) (Style, error) {
+	for _, seen := range chain {
+		if seen == name {
+			chain = append(chain, name)
+			return Style{}, fmt.Errorf("Style inherits from itself: %s", strings.Join(chain, " -> "))
+		}
+	}
+	if style, ok := r.Styles[name]; ok {
+		return style, nil
+	}
+	derived, ok := r.derivedStyles[name]
+	if !ok {
+		if len(chain) > 0 {
+			return Style{}, fmt.Errorf(
+				"Could not find parent style name of %s in Report: %s",
+				chain[len(chain)-1],
+				name,
+			)
+		}
+		return Style{}, fmt.Errorf("Could not find style name in Report: %s", name)
+	}
+	style, err := r.resolveStyle(derived.parent, append(chain, name))
+	if err != nil {
+		return style, err
+	}
+	for _, override := range derived.overrides {
+		override(&style)
+	}
+	return style, nil
+}
@@ -0,0 +1,83 @@
+package tps
+
+import (
+	"testing"
+)
+
+func TestAddStyleFrom(t *testing.T) {
+	r, _ := NewReport()
+	r.AddStyle("body", "Helvetica", "", 10, AlignLeft)
+	if err := r.AddStyleFrom("total", "body", StyleBold(), StyleAlignment(AlignRight)); err != nil {
+		t.Fatalf("AddStyleFrom returned an error: %v", err)
+	}
+	if err := r.AddStyleFrom("grand total", "total", StyleFontSize(12)); err != nil {
+		t.Fatalf("AddStyleFrom returned an error: %v", err)
+	}
+
+	e := Style{FontFamily: "Helvetica", FontStyle: "B", FontSize: 12, Alignment: AlignRight}
+	if s, err := r.Style("grand total"); err != nil || s != e {
+		t.Errorf("Style did not resolve parents. Got %v %v expected %v", s, err, e)
+	}
+
+	// changes to the parent cascade
+	r.AddStyle("body", "Courier", "", 10, AlignLeft)
+	e.FontFamily = "Courier"
+	if s, _ := r.Style("grand total"); s != e {
+		t.Errorf("Style did not cascade parent changes. Got %v expected %v", s, e)
+	}
+
+	e = Style{FontFamily: "Courier", FontStyle: "I", FontSize: 10, Alignment: AlignLeft}
+	if s, _ := r.Style("body", StyleItalic(), StyleItalic()); s != e {
+		t.Errorf("Style did not apply overrides. Got %v expected %v", s, e)
+	}
+}
+
+func TestAddStyleFromErrors(t *testing.T) {
+	r, _ := NewReport()
+	r.AddStyle("body", "Helvetica", "", 10, AlignLeft)
+	if err := r.AddStyleFrom("total", "missing"); err == nil {
+		t.Errorf("AddStyleFrom did not return an error for an unknown parent")
+	}
+	if _, ok := r.derivedStyles["total"]; ok {
+		t.Errorf("AddStyleFrom kept a style with an unknown parent")
+	}
+
+	r.AddStyleFrom("a", "body")
+	r.AddStyleFrom("b", "a")
+	if err := r.AddStyleFrom("body", "b"); err == nil {
+		t.Errorf("AddStyleFrom did not return an error for a cycle")
+	}
+	if s, err := r.Style("b"); err != nil || s.FontFamily != "Helvetica" {
+		t.Errorf("AddStyleFrom did not restore the style replaced by a cycle. Got %v %v", s, err)
+	}
+}
+
+func TestContentOverrides(t *testing.T) {
+	r := newFlowReport(t)
+	r.AddPage()
+	if _, err := r.Content(1, 1, "full", "body", "Total", StyleBold(), StyleFontSize(12)); err != nil {
+		t.Errorf("Content returned an error with overrides: %v", err)
+	}
+	normal, _ := r.Measure("full", "body", "Total")
+	large, _ := r.Measure("full", "body", "Total", StyleFontSize(300))
+	if large.LineCount <= normal.LineCount {
+		t.Errorf("Measure did not apply overrides. Got %d lines expected more than %d", large.LineCount, normal.LineCount)
+	}
+}
+
+func TestRichAndFrameOverrides(t *testing.T) {
+	r := newFlowReport(t)
+	r.AddPage()
+	lineCount, err := r.RichContent(1, 1, "full", "body", "<b>Total</b>", StyleFontSize(300))
+	if err != nil || lineCount <= 1 {
+		t.Errorf("RichContent did not apply overrides. Got %d lines error %v", lineCount, err)
+	}
+	lineCount, err = r.FlowRich("full", "body", "Total", StyleFontSize(300))
+	if err != nil || lineCount <= 1 {
+		t.Errorf("FlowRich did not apply overrides. Got %d lines error %v", lineCount, err)
+	}
+	leftover, err := r.FlowInto([]Frame{{X: 1, Y: 1, Width: 12, Height: 1}}, "body", "Total", StyleFontSize(300))
+	if err != nil || leftover == "" {
+		t.Errorf("FlowInto did not apply overrides. Got %q error %v", leftover, err)
+	}
+}
@@ -0,0 +1,108 @@
+package tps
+
+import (
+	"fmt"
+)
+
+// LineStyle is a specification of the lines drawn by Rule(), Box() and
+// GutterRule(). Width is in the Grid.Unit system. Dash is the lengths of
+// alternating dashes and gaps in the Grid.Unit system, and an empty Dash
+// draws a solid line.
+type LineStyle struct {
+	Width float64
+	Color Color
+	Dash  []float64
+}
+
+// AddLineStyle adds a new line style to use when drawing lines in this report.
+// For example, a thin grey dashed line:
+//
+//	r.AddLineStyle("dashed", 0.5, Color{128, 128, 128}, 2, 1)
+func (r *Report) AddLineStyle(name string, width float64, color Color, dash ...float64) {
+	r.LineStyles[name] = LineStyle{
+		Width: width,
+		Color: color,
+		Dash:  dash,
+	}
+}
+
+// Rule draws a horizontal line along the top of line y, starting at column x
+// and spanning width columns and the gutters between them like Block.Width.
+func (r *Report) Rule(x, y, width int, lineStyleName string) error {
+	lineStyle, err := r.lookupLineStyle(lineStyleName)
+	if err != nil {
+		return err
+	}
+	point := r.Grid.GetPoint(x, y)
+	cell := r.Grid.GetCell(Block{Width: width, Height: 1})
+	defer r.setLineStyle(lineStyle)()
+	r.Pdf.Line(point.X, point.Y, point.X+cell.Width, point.Y)
+	return nil
+}
+
+// Box draws a rectangle around the named block placed at x, y.
+func (r *Report) Box(x, y int, blockName, lineStyleName string) error {
+	block, ok := r.Blocks[blockName]
+	if !ok {
+		return fmt.Errorf("Could not find block name in Report: %s", blockName)
+	}
+	lineStyle, err := r.lookupLineStyle(lineStyleName)
+	if err != nil {
+		return err
+	}
+	point := r.Grid.GetPoint(x, y)
+	cell := r.Grid.GetCell(block)
+	defer r.setLineStyle(lineStyle)()
+	r.Pdf.Rect(point.X, point.Y, cell.Width, cell.Height, "D")
+	return nil
+}
+
+// GutterRule draws a vertical line down the middle of the gutter to the right
+// of column x, from the top of line y and spanning height lines.
+func (r *Report) GutterRule(x, y, height int, lineStyleName string) error {
+	if x < 1 || x >= r.Grid.ColumnCount {
+		return fmt.Errorf("There is no gutter after column %d", x)
+	}
+	lineStyle, err := r.lookupLineStyle(lineStyleName)
+	if err != nil {
+		return err
+	}
+	point := r.Grid.GetPoint(x+1, y)
+	lineX := point.X - r.Grid.GutterWidth/2
+	lineHeight := r.Grid.LineHeight * float64(height)
+	defer r.setLineStyle(lineStyle)()
+	r.Pdf.Line(lineX, point.Y, lineX, point.Y+lineHeight)
+	return nil
+}
+
+// GutterRules draws a GutterRule() in every gutter of the grid.
+func (r *Report) GutterRules(y, height int, lineStyleName string) error {
+	for x := 1; x < r.Grid.ColumnCount; x++ {
+		if err := r.GutterRule(x, y, height, lineStyleName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Report) lookupLineStyle(name string) (LineStyle, error) {
+	lineStyle, ok := r.LineStyles[name]
+	if !ok {
+		return lineStyle, fmt.Errorf("Could not find line style name in Report: %s", name)
+	}
+	return lineStyle, nil
+}
+
+// setLineStyle sets the line style for drawing and returns a func restoring
+// the previous drawing state.
+func (r *Report) setLineStyle(lineStyle LineStyle) func() {
+	r.startPage()
+	restore := r.saveDrawing()
+	r.Pdf.SetLineWidth(lineStyle.Width)
+	r.Pdf.SetDrawColor(lineStyle.Color.R, lineStyle.Color.G, lineStyle.Color.B)
+	r.Pdf.SetDashPattern(lineStyle.Dash, 0)
+	return func() {
+		r.Pdf.SetDashPattern([]float64{}, 0)
+		restore()
+	}
+}
@@ -0,0 +1,48 @@
+package tps
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddLineStyle(t *testing.T) {
+	r, _ := NewReport()
+	r.AddLineStyle("dashed", 0.5, Color{128, 128, 128}, 2, 1)
+	e := LineStyle{0.5, Color{128, 128, 128}, []float64{2, 1}}
+	if s := r.LineStyles["dashed"]; !reflect.DeepEqual(s, e) {
+		t.Errorf("AddLineStyle did not store line style correctly. Got %v expected %v", s, e)
+	}
+}
+
+func TestLines(t *testing.T) {
+	r := newFlowReport(t)
+	r.AddLineStyle("thin", 0.5, Color{0, 0, 0})
+	r.AddLineStyle("dashed", 1, Color{255, 0, 0}, 2, 1)
+	r.Pdf.SetLineWidth(3)
+
+	if err := r.Rule(1, 2, 12, "thin"); err != nil {
+		t.Errorf("Rule returned an error: %v", err)
+	}
+	if err := r.Box(1, 3, "double", "dashed"); err != nil {
+		t.Errorf("Box returned an error: %v", err)
+	}
+	if err := r.GutterRules(1, 10, "thin"); err != nil {
+		t.Errorf("GutterRules returned an error: %v", err)
+	}
+	if w := r.Pdf.GetLineWidth(); w != 3 {
+		t.Errorf("Line drawing did not restore line width. Got %.1f expected %.1f", w, 3.0)
+	}
+
+	if err := r.Rule(1, 1, 1, "missing"); err == nil {
+		t.Errorf("Rule did not return an error for an unknown line style")
+	}
+	if err := r.Box(1, 1, "missing", "thin"); err == nil {
+		t.Errorf("Box did not return an error for an unknown block")
+	}
+	if err := r.GutterRule(12, 1, 1, "thin"); err == nil {
+		t.Errorf("GutterRule did not return an error for the last column")
+	}
+	if _, err := r.Bytes(); err != nil {
+		t.Errorf("Bytes returned an error with lines: %v", err)
+	}
+}
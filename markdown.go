@@ -0,0 +1,360 @@
+package tps
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// MarkdownTheme names the styles, block and line style RenderMarkdown() uses.
+//
+// Block is the block all text is placed in, usually the full width of the
+// grid, and lists, quotes and tables are narrowed from it. Headings are the
+// styles of heading levels 1 to 6, where an empty name uses the level above.
+// Code is the style of code blocks and inline code, Quote of block quotes, and
+// TableHeader and Table of table cells. Empty style names other than the
+// headings use Paragraph. Rule is the line style of horizontal rules, which
+// are skipped when it is empty. Bullet marks unordered list items and
+// defaults to "-". ListIndent and QuoteIndent are the # of columns each
+// nested list or quote is indented, and Spacing is the # of lines left empty
+// after each paragraph, heading, list, code block, table and rule.
+type MarkdownTheme struct {
+	Block       string
+	Paragraph   string
+	Headings    [6]string
+	Code        string
+	Quote       string
+	TableHeader string
+	Table       string
+	Rule        string
+	Bullet      string
+	ListIndent  int
+	QuoteIndent int
+	Spacing     int
+}
+
+// markdownRenderer holds the state of one RenderMarkdown() call.
+type markdownRenderer struct {
+	r         *Report
+	theme     MarkdownTheme
+	source    []byte
+	block     Block
+	x         int
+	paragraph string
+}
+
+// RenderMarkdown places a Markdown document at the cursor of the report using
+// the theme, flowing across pages like Report.Flow(). Headings, paragraphs,
+// lists, block quotes, code, emphasis, tables and horizontal rules are
+// supported. Links are placed as their text and images are skipped.
+func RenderMarkdown(r *Report, md io.Reader, theme MarkdownTheme) error {
+	source, err := ioutil.ReadAll(md)
+	if err != nil {
+		return fmt.Errorf("Could not read Markdown: %v", err)
+	}
+	block, ok := r.Blocks[theme.Block]
+	if !ok {
+		return fmt.Errorf("Could not find block name in Report: %s", theme.Block)
+	}
+	theme = theme.withDefaults()
+	for _, name := range theme.styleNames() {
+		if _, err = r.Style(name); err != nil {
+			return err
+		}
+	}
+	if theme.Rule != "" {
+		if _, err = r.lookupLineStyle(theme.Rule); err != nil {
+			return err
+		}
+	}
+
+	parser := goldmark.New(goldmark.WithExtensions(extension.Table)).Parser()
+	doc := parser.Parse(text.NewReader(source))
+	m := &markdownRenderer{
+		r:         r,
+		theme:     theme,
+		source:    source,
+		block:     block,
+		x:         r.Cursor.X,
+		paragraph: theme.Paragraph,
+	}
+	defer func() {
+		r.Cursor.X = m.x
+	}()
+	return m.renderChildren(doc, 0)
+}
+
+// withDefaults fills in the empty names of the theme.
+func (t MarkdownTheme) withDefaults() MarkdownTheme {
+	previous := t.Paragraph
+	for i, heading := range t.Headings {
+		if heading == "" {
+			t.Headings[i] = previous
+		}
+		previous = t.Headings[i]
+	}
+	for _, name := range []*string{&t.Code, &t.Quote, &t.Table} {
+		if *name == "" {
+			*name = t.Paragraph
+		}
+	}
+	if t.TableHeader == "" {
+		t.TableHeader = t.Table
+	}
+	if t.Bullet == "" {
+		t.Bullet = "-"
+	}
+	return t
+}
+
+func (t MarkdownTheme) styleNames() []string {
+	return append(t.Headings[:], t.Paragraph, t.Code, t.Quote, t.TableHeader, t.Table)
+}
+
+func (m *markdownRenderer) renderChildren(node ast.Node, indent int) error {
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if err := m.render(child, indent, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render places one block node indented by # of columns. A marker is placed
+// before the text of list items.
+func (m *markdownRenderer) render(node ast.Node, indent int, marker string) error {
+	switch n := node.(type) {
+	case *ast.Heading:
+		return m.text(n, indent, m.theme.Headings[n.Level-1], marker, true)
+	case *ast.Paragraph:
+		return m.text(n, indent, m.paragraph, marker, true)
+	case *ast.TextBlock:
+		return m.text(n, indent, m.paragraph, marker, false)
+	case *ast.List:
+		return m.list(n, indent)
+	case *ast.Blockquote:
+		paragraph := m.paragraph
+		m.paragraph = m.theme.Quote
+		err := m.renderChildren(n, indent+m.theme.QuoteIndent)
+		m.paragraph = paragraph
+		return err
+	case *ast.FencedCodeBlock, *ast.CodeBlock:
+		return m.code(n, indent)
+	case *ast.ThematicBreak:
+		return m.rule(indent)
+	case *east.Table:
+		return m.table(n, indent)
+	}
+	return nil
+}
+
+// at moves the cursor to the indented column and returns the block narrowed
+// to fit from there.
+func (m *markdownRenderer) at(indent int) (Block, error) {
+	block := m.block
+	block.Width -= indent
+	if block.Width < 1 {
+		return block, fmt.Errorf("Markdown is nested too deep for block: %s", m.theme.Block)
+	}
+	m.r.Cursor.X = m.x + indent
+	return block, nil
+}
+
+func (m *markdownRenderer) space() {
+	m.r.Cursor.Y += m.theme.Spacing
+}
+
+func (m *markdownRenderer) text(node ast.Node, indent int, styleName, marker string, space bool) error {
+	block, err := m.at(indent)
+	if err != nil {
+		return err
+	}
+	style, _ := m.r.Style(styleName)
+	markup := m.inline(node, true)
+	if marker != "" {
+		markup = escapeRich(marker) + " " + markup
+	}
+	if _, err = m.r.flowRich(block, style, markup, m.theme.Block); err != nil {
+		return err
+	}
+	if space {
+		m.space()
+	}
+	return nil
+}
+
+func (m *markdownRenderer) list(list *ast.List, indent int) error {
+	number := list.Start
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		marker := m.theme.Bullet
+		if list.IsOrdered() {
+			marker = fmt.Sprintf("%d%c", number, list.Marker)
+			number++
+		}
+		for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+			if err := m.render(child, indent+m.theme.ListIndent, marker); err != nil {
+				return err
+			}
+			marker = ""
+		}
+	}
+	// nested lists are spaced by the item around them
+	if _, nested := list.Parent().(*ast.ListItem); !nested {
+		m.space()
+	}
+	return nil
+}
+
+func (m *markdownRenderer) code(node ast.Node, indent int) error {
+	block, err := m.at(indent)
+	if err != nil {
+		return err
+	}
+	var code strings.Builder
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		code.Write(segment.Value(m.source))
+	}
+	style, _ := m.r.Style(m.theme.Code)
+	if _, err = m.r.flowText(block, style, strings.TrimRight(code.String(), "\n"), m.theme.Block); err != nil {
+		return err
+	}
+	m.space()
+	return nil
+}
+
+func (m *markdownRenderer) rule(indent int) error {
+	if m.theme.Rule == "" {
+		return nil
+	}
+	block, err := m.at(indent)
+	if err != nil {
+		return err
+	}
+	// a rule is drawn along the top of a line, so it needs one line left
+	m.r.startPage()
+	if m.r.Cursor.Y > m.r.Grid.LineCount() {
+		m.r.AddPage()
+	}
+	if err = m.r.Rule(m.r.Cursor.X, m.r.Cursor.Y, block.Width, m.theme.Rule); err != nil {
+		return err
+	}
+	m.r.Cursor.Y++
+	m.space()
+	return nil
+}
+
+func (m *markdownRenderer) table(node *east.Table, indent int) error {
+	block, err := m.at(indent)
+	if err != nil {
+		return err
+	}
+	columnCount := len(node.Alignments)
+	if columnCount == 0 || block.Width < columnCount {
+		return fmt.Errorf("Markdown table has %d columns and does not fit block: %s", columnCount, m.theme.Block)
+	}
+	t := &Table{Height: m.block.Height}
+	for i, alignment := range node.Alignments {
+		column := TableColumn{
+			Width:       block.Width / columnCount,
+			Style:       m.theme.Table,
+			HeaderStyle: m.theme.TableHeader,
+		}
+		if i == columnCount-1 {
+			column.Width += block.Width % columnCount
+		}
+		switch alignment {
+		case east.AlignLeft:
+			column.Alignment = AlignLeft
+		case east.AlignCenter:
+			column.Alignment = AlignCenter
+		case east.AlignRight:
+			column.Alignment = AlignRight
+		}
+		t.Columns = append(t.Columns, column)
+	}
+	for row := node.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, m.inline(cell, false))
+		}
+		if _, header := row.(*east.TableHeader); header {
+			t.Header = append(t.Header, cells)
+		} else {
+			t.Rows = append(t.Rows, cells)
+		}
+	}
+	if _, err = m.r.FlowTable(t); err != nil {
+		return err
+	}
+	m.space()
+	return nil
+}
+
+// inline converts the inline children of node into rich content markup, or
+// into plain text when rich is false.
+func (m *markdownRenderer) inline(node ast.Node, rich bool) string {
+	var b strings.Builder
+	m.writeInline(&b, node, rich)
+	return b.String()
+}
+
+func (m *markdownRenderer) writeInline(b *strings.Builder, node ast.Node, rich bool) {
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		openTag, closeTag := "", ""
+		switch n := child.(type) {
+		case *ast.Text:
+			m.writeText(b, string(n.Segment.Value(m.source)), rich)
+			if n.HardLineBreak() {
+				b.WriteString("\n")
+			} else if n.SoftLineBreak() {
+				b.WriteString(" ")
+			}
+			continue
+		case *ast.String:
+			m.writeText(b, string(n.Value), rich)
+			continue
+		case *ast.AutoLink:
+			m.writeText(b, string(n.Label(m.source)), rich)
+			continue
+		case *ast.RawHTML:
+			for i := 0; i < n.Segments.Len(); i++ {
+				segment := n.Segments.At(i)
+				m.writeText(b, string(segment.Value(m.source)), rich)
+			}
+			continue
+		case *ast.Image:
+			continue
+		case *ast.Emphasis:
+			openTag, closeTag = "<i>", "</i>"
+			if n.Level > 1 {
+				openTag, closeTag = "<b>", "</b>"
+			}
+		case *ast.CodeSpan:
+			openTag = fmt.Sprintf(`<style name="%s">`, escapeRich(m.theme.Code))
+			closeTag = "</style>"
+		}
+		if rich {
+			b.WriteString(openTag)
+		}
+		m.writeInline(b, child, rich)
+		if rich {
+			b.WriteString(closeTag)
+		}
+	}
+}
+
+func (m *markdownRenderer) writeText(b *strings.Builder, value string, rich bool) {
+	if rich {
+		value = escapeRich(value)
+	}
+	b.WriteString(value)
+}
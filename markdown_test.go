@@ -0,0 +1,87 @@
+package tps
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMarkdown = "# Release *notes*\n\n" +
+	"Some **bold** text with `code` and a [link](http://example.com).\n\n" +
+	"## Changes\n\n" +
+	"- first\n" +
+	"- second\n" +
+	"  1. nested\n" +
+	"  2. nested again\n\n" +
+	"> quoted text\n\n" +
+	"```\nfunc main() {\n\treturn\n}\n```\n\n" +
+	"---\n\n" +
+	"| Item | Amount |\n" +
+	"|:-----|-------:|\n" +
+	"| a    | 1.00   |\n" +
+	"| b    | 2.00   |\n"
+
+func newMarkdownReport(t *testing.T) (*Report, MarkdownTheme) {
+	r := newFlowReport(t)
+	r.AddStyle("h1", "Helvetica", "B", 18, AlignLeft)
+	r.AddStyle("h2", "Helvetica", "B", 14, AlignLeft)
+	r.AddStyle("code", "Courier", "", 9, AlignLeft)
+	r.AddLineStyle("rule", 0.5, Color{128, 128, 128})
+	theme := MarkdownTheme{
+		Block:      "full",
+		Paragraph:  "body",
+		Headings:   [6]string{"h1", "h2"},
+		Code:       "code",
+		Rule:       "rule",
+		ListIndent: 1,
+		Spacing:    1,
+	}
+	return r, theme
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	r, theme := newMarkdownReport(t)
+	if err := RenderMarkdown(r, strings.NewReader(testMarkdown), theme); err != nil {
+		t.Fatalf("RenderMarkdown returned an error: %v", err)
+	}
+	// 2 headings, paragraph, 4 list items, quote, 3 code lines, rule, 3 table
+	// rows and 8 lines of spacing
+	if r.Cursor.Y != 24 {
+		t.Errorf("RenderMarkdown did not flow content. Got cursor %d expected %d", r.Cursor.Y, 24)
+	}
+	if r.Cursor.X != 1 {
+		t.Errorf("RenderMarkdown did not restore the cursor column. Got %d", r.Cursor.X)
+	}
+
+	r, theme = newMarkdownReport(t)
+	long := strings.Repeat("A paragraph.\n\n", 40)
+	if err := RenderMarkdown(r, strings.NewReader(long), theme); err != nil {
+		t.Fatalf("RenderMarkdown returned an error: %v", err)
+	}
+	if r.Pdf.PageNo() != 2 {
+		t.Errorf("RenderMarkdown did not flow across pages. Got page %d expected %d", r.Pdf.PageNo(), 2)
+	}
+	if _, err := r.Bytes(); err != nil {
+		t.Errorf("Bytes returned an error with Markdown: %v", err)
+	}
+}
+
+func TestRenderMarkdownErrors(t *testing.T) {
+	r, theme := newMarkdownReport(t)
+	theme.Block = "missing"
+	if err := RenderMarkdown(r, strings.NewReader("text"), theme); err == nil {
+		t.Errorf("RenderMarkdown did not return an error for an unknown block")
+	}
+
+	r, theme = newMarkdownReport(t)
+	theme.Code = "missing"
+	if err := RenderMarkdown(r, strings.NewReader("text"), theme); err == nil {
+		t.Errorf("RenderMarkdown did not return an error for an unknown style")
+	}
+
+	r, theme = newMarkdownReport(t)
+	theme.ListIndent = 6
+	deep := "- a\n  - b\n    - c\n"
+	if err := RenderMarkdown(r, strings.NewReader(deep), theme); err == nil {
+		t.Errorf("RenderMarkdown did not return an error for lists nested too deep")
+	}
+}
@@ -0,0 +1,214 @@
+package tps
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// Option configures a Report created with NewReport.
+type Option func(*options) error
+
+// options collects everything passed to NewReport so it can be validated and
+// applied in a fixed order, regardless of the order the options were given.
+type options struct {
+	grid         *Grid
+	pageWidth    float64
+	pageHeight   float64
+	margins      *Margins
+	baseline     *float64
+	fontPath     string
+	fontFS       fs.FS
+	fontCacheDir string
+	styles       map[string]Style
+	blocks       map[string]Block
+}
+
+// WithGrid sets the page and grid specifications. The arguments are the same
+// as Report.SetGrid().
+func WithGrid(
+	orientation int,
+	pageSize int,
+	unit int,
+	margin float64,
+	columnCount int,
+	gutterWidth float64,
+	lineHeight float64,
+) Option {
+	return func(o *options) error {
+		g := Grid{
+			Orientation: orientation,
+			PageSize:    pageSize,
+			Unit:        unit,
+			Margin:      margin,
+			ColumnCount: columnCount,
+			GutterWidth: gutterWidth,
+			LineHeight:  lineHeight,
+		}
+		if err := g.validate(); err != nil {
+			return err
+		}
+		o.grid = &g
+		return nil
+	}
+}
+
+// WithPageDimensions sets the portrait page width and height in the grid unit
+// for PageSizeCustom, such as 80 x 200 for a receipt in UnitMm. It needs
+// WithGrid() with PageSizeCustom.
+func WithPageDimensions(width, height float64) Option {
+	return func(o *options) error {
+		if width <= 0 || height <= 0 {
+			return errors.New("Custom page width and height must be positive")
+		}
+		o.pageWidth = width
+		o.pageHeight = height
+		return nil
+	}
+}
+
+// WithMargins sets separate or mirrored margins in place of the margin given
+// to WithGrid. See Report.SetMargins().
+func WithMargins(margins Margins) Option {
+	return func(o *options) error {
+		if err := margins.validate(); err != nil {
+			return err
+		}
+		o.margins = &margins
+		return nil
+	}
+}
+
+// WithBaselineGrid turns on the baseline grid with the baseline offset above
+// the bottom of each grid line. See Report.SetBaselineGrid().
+func WithBaselineGrid(offset float64) Option {
+	return func(o *options) error {
+		if offset < 0 {
+			return errors.New("Baseline offset cannot be negative")
+		}
+		o.baseline = &offset
+		return nil
+	}
+}
+
+// WithFontPath sets the directory fonts are loaded from. See
+// Report.SetFontPath().
+func WithFontPath(fontSourcePath string) Option {
+	return func(o *options) error {
+		info, err := os.Stat(fontSourcePath)
+		if err != nil {
+			return fmt.Errorf("Could not use font path: %v", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("Font path is not a directory: %s", fontSourcePath)
+		}
+		o.fontPath = fontSourcePath
+		return nil
+	}
+}
+
+// WithFontFS sets the fs.FS fonts are loaded from, such as an embed.FS. See
+// Report.SetFontFS().
+func WithFontFS(fsys fs.FS) Option {
+	return func(o *options) error {
+		if fsys == nil {
+			return errors.New("Font FS is nil")
+		}
+		o.fontFS = fsys
+		return nil
+	}
+}
+
+// WithFontCacheDir sets the directory compiled fonts are kept in, which is
+// created when needed. It is used with WithFontPath() or WithFontFS(). See
+// Report.SetFontCacheDir().
+func WithFontCacheDir(dir string) Option {
+	return func(o *options) error {
+		if dir == "" {
+			return errors.New("Font cache directory is empty")
+		}
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			return fmt.Errorf("Font cache directory is not a directory: %s", dir)
+		}
+		o.fontCacheDir = dir
+		return nil
+	}
+}
+
+// WithStyles adds named styles to the Report as if passed to Report.AddStyle().
+// It can be given more than once.
+func WithStyles(styles map[string]Style) Option {
+	return func(o *options) error {
+		for name, style := range styles {
+			o.styles[name] = style
+		}
+		return nil
+	}
+}
+
+// WithBlocks adds named blocks to the Report as if passed to Report.AddBlock().
+// It can be given more than once.
+func WithBlocks(blocks map[string]Block) Option {
+	return func(o *options) error {
+		for name, block := range blocks {
+			if block.Width <= 0 || block.Height <= 0 {
+				return fmt.Errorf("Block width and height must be positive: %s", name)
+			}
+			o.blocks[name] = block
+		}
+		return nil
+	}
+}
+
+// validate checks the options against each other once they are all collected.
+func (o *options) validate() error {
+	if o.fontPath != "" && o.fontFS != nil {
+		return errors.New("Fonts can be loaded from a font path or a font FS but not both")
+	}
+	if o.fontCacheDir != "" && o.fontPath == "" && o.fontFS == nil {
+		return errors.New("Font cache directory needs a font path or a font FS")
+	}
+	if o.grid == nil {
+		if o.pageWidth > 0 {
+			return errors.New("Page dimensions need a grid with a custom page size")
+		}
+		return nil
+	}
+	if o.pageWidth > 0 {
+		if o.grid.PageSize != PageSizeCustom {
+			return fmt.Errorf(
+				"Page dimensions need a custom page size, not %s",
+				o.grid.convertPageSize(),
+			)
+		}
+		o.grid.CustomWidth = o.pageWidth
+		o.grid.CustomHeight = o.pageHeight
+	}
+	if err := o.grid.validatePageDimensions(); err != nil {
+		return err
+	}
+	if o.margins != nil {
+		o.grid.Margins = *o.margins
+	}
+	if err := o.grid.fit(); err != nil {
+		return err
+	}
+	if o.baseline != nil {
+		if *o.baseline >= o.grid.LineHeight {
+			return errors.New("Baseline offset must be less than the line height")
+		}
+		o.grid.BaselineGrid = true
+		o.grid.BaselineOffset = *o.baseline
+	}
+	for name, block := range o.blocks {
+		if block.Width > o.grid.ColumnCount {
+			return fmt.Errorf(
+				"Block is wider than the grid column count of %d: %s",
+				o.grid.ColumnCount,
+				name,
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package tps
+
+import (
+	"testing"
+)
+
+func TestNewReportWithOptions(t *testing.T) {
+	dir := t.TempDir()
+	r, err := NewReport(
+		WithFontPath(dir),
+		WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36.0, 12, 12.0, 12.0),
+		WithStyles(map[string]Style{"body": {FontFamily: "Helvetica", FontSize: 10, Alignment: AlignLeft}}),
+		WithBlocks(map[string]Block{"full": {12, 1}}),
+	)
+	if err != nil {
+		t.Fatalf("NewReport returned an error: %v", err)
+	}
+	if r.Pdf == nil {
+		t.Errorf("NewReport did not initialize Pdf")
+	}
+	if r.Grid.ColumnWidth != 34.0 {
+		t.Errorf("NewReport did not calculate grid columns. Got %.1f expected %.1f", r.Grid.ColumnWidth, 34.0)
+	}
+	if r.FontSourcePath != dir {
+		t.Errorf("NewReport did not set font path. Got %s expected %s", r.FontSourcePath, dir)
+	}
+	if _, ok := r.Styles["body"]; !ok {
+		t.Errorf("NewReport did not add styles")
+	}
+	if _, ok := r.Blocks["full"]; !ok {
+		t.Errorf("NewReport did not add blocks")
+	}
+}
+
+func TestNewReportInvalidOptions(t *testing.T) {
+	tests := map[string][]Option{
+		"orientation": {WithGrid(-1, PageSizeLetter, UnitPt, 36.0, 12, 12.0, 12.0)},
+		"page size":   {WithGrid(OrientationPortrait, -1, UnitPt, 36.0, 12, 12.0, 12.0)},
+		"columns":     {WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36.0, 0, 12.0, 12.0)},
+		"font path":   {WithFontPath("/does/not/exist")},
+		"block size":  {WithBlocks(map[string]Block{"empty": {0, 1}})},
+		"block width": {
+			WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36.0, 12, 12.0, 12.0),
+			WithBlocks(map[string]Block{"wide": {13, 1}}),
+		},
+	}
+	for name, opts := range tests {
+		if r, err := NewReport(opts...); err == nil || r != nil {
+			t.Errorf("NewReport did not reject invalid %s option", name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tps
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Renderer creates many reports from one Template concurrently, such as the
+// same statement for thousands of customers.
+type Renderer struct {
+	Template *Template
+	// Workers is the # of reports created at once. It defaults to the # of CPUs.
+	Workers int
+}
+
+// NewRenderer returns a Renderer for the template with the # of workers.
+func NewRenderer(t *Template, workers int) *Renderer {
+	return &Renderer{Template: t, Workers: workers}
+}
+
+// Render executes the template against each element of data and passes the
+// Report to output with the index of its data. output is called from many
+// goroutines at once, usually to save the report:
+//
+//	errs := renderer.Render(ctx, customers, func(i int, r *Report) error {
+//		return r.Save(fmt.Sprintf("statement-%d.pdf", i))
+//	})
+//
+// The returned errors are one per element of data, nil for each report that
+// was created and output without error. When ctx is done the reports not yet
+// started are skipped with the error of ctx.
+func (rr *Renderer) Render(
+	ctx context.Context,
+	data []interface{},
+	output func(index int, r *Report) error,
+) []error {
+	workers := rr.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	errs := make([]error, len(data))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = rr.render(ctx, i, data[i], output)
+			}
+		}()
+	}
+
+send:
+	for i := range data {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			for ; i < len(data); i++ {
+				errs[i] = ctx.Err()
+			}
+			break send
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return errs
+}
+
+// render creates and outputs one report unless ctx is already done.
+func (rr *Renderer) render(
+	ctx context.Context,
+	index int,
+	data interface{},
+	output func(int, *Report) error,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r, err := rr.Template.Execute(data)
+	if err != nil {
+		return err
+	}
+	return output(index, r)
+}
@@ -0,0 +1,86 @@
+package tps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newRenderTemplate(t *testing.T) *Template {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "Go-Regular.ttf"), goregular.TTF, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := fmt.Sprintf(`
+grid: {orientation: portrait, pageSize: letter, unit: pt, margin: 36, columns: 12, gutter: 12, lineHeight: 12}
+fontPath: %q
+fonts:
+  - {file: Go-Regular.ttf, encoding: cp1252}
+styles:
+  body: {font: Go-Regular, size: 10}
+blocks:
+  full: {width: 12, height: 1}
+content:
+  - {x: 1, y: 1, block: full, style: body, text: "Statement for {{.Name}}"}
+`, dir)
+	tmpl, err := ParseTemplate(strings.NewReader(spec))
+	if err != nil {
+		t.Fatalf("ParseTemplate returned an error: %v", err)
+	}
+	return tmpl
+}
+
+func TestRender(t *testing.T) {
+	renderer := NewRenderer(newRenderTemplate(t), 4)
+	data := make([]interface{}, 20)
+	for i := range data {
+		data[i] = map[string]string{"Name": fmt.Sprintf("customer %d", i)}
+	}
+	data[7] = map[string]string{}
+
+	var count int32
+	errs := renderer.Render(context.Background(), data, func(i int, r *Report) error {
+		if _, err := r.Bytes(); err != nil {
+			return err
+		}
+		atomic.AddInt32(&count, 1)
+		return nil
+	})
+	for i, err := range errs {
+		if (err != nil) != (i == 7) {
+			t.Errorf("Render returned the wrong error for document %d: %v", i, err)
+		}
+	}
+	if count != 19 {
+		t.Errorf("Render did not output every report. Got %d expected %d", count, 19)
+	}
+}
+
+func TestRenderCancel(t *testing.T) {
+	renderer := NewRenderer(newRenderTemplate(t), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	data := make([]interface{}, 4)
+	for i := range data {
+		data[i] = map[string]string{"Name": "a"}
+	}
+	errs := renderer.Render(ctx, data, func(i int, r *Report) error {
+		cancel()
+		return nil
+	})
+	if errs[0] != nil {
+		t.Errorf("Render returned an error for the report before cancelling: %v", errs[0])
+	}
+	for _, err := range errs[2:] {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Render did not skip a report after cancelling. Got %v", err)
+		}
+	}
+}
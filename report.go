@@ -1,15 +1,19 @@
 package tps
 
 import (
-	"encoding/base64"
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"math"
+	"io/fs"
+	"io/ioutil"
 	"os"
 	"path"
 	"strings"
+	"unicode"
 
 	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/font/sfnt"
 )
 
 // Report is the main struct type that holds all information to generate a PDF.
@@ -18,69 +22,361 @@ type Report struct {
 	Pdf              *gofpdf.Fpdf
 	Styles           map[string]Style
 	Blocks           map[string]Block
+	LineStyles       map[string]LineStyle
+	Cursor           Cursor
 	FontSourcePath   string
 	FontCompiledPath string
+	SkipFirstHeader  bool
+	SkipFirstFooter  bool
+
+	header     []PageContent
+	footer     []PageContent
+	debug      bool
+	utf8Fonts  map[string]*sfnt.Font
+	fontFS     fs.FS
+	cellMargin float64
+	pageGrid   *Grid
+
+	derivedStyles map[string]derivedStyle
+}
+
+// OutputError is returned when the report could not be finished and written
+// out. Op is the output method that failed and Err is the underlying error,
+// either from writing or accumulated by Pdf while placing content.
+type OutputError struct {
+	Op  string
+	Err error
+}
+
+func (e *OutputError) Error() string {
+	return fmt.Sprintf("Could not %s report: %v", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error so errors.Is and errors.As can inspect it.
+func (e *OutputError) Unwrap() error {
+	return e.Err
 }
 
-func NewReport() *Report {
+// NewReport creates a Report configured by the given options. The options are
+// validated together and applied in a fixed order, the fonts first since the
+// grid creates Pdf with the font path, then the grid, so a Report returned
+// without error is ready to place content when WithGrid is given. For example:
+//
+//   r, err := NewReport(
+//   	WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36, 12, 12, 12),
+//   	WithFontPath("fonts"),
+//   	WithBlocks(map[string]Block{"full": {12, 1}}),
+//   )
+func NewReport(opts ...Option) (*Report, error) {
+	o := &options{
+		styles: make(map[string]Style),
+		blocks: make(map[string]Block),
+	}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
 	report := new(Report)
-	report.Styles = make(map[string]Style)
-	report.Blocks = make(map[string]Block)
-	return report
+	report.Styles = o.styles
+	report.Blocks = o.blocks
+	report.LineStyles = make(map[string]LineStyle)
+	report.derivedStyles = make(map[string]derivedStyle)
+	report.Cursor = Cursor{X: 1, Y: 1}
+	report.utf8Fonts = make(map[string]*sfnt.Font)
+	if o.fontPath != "" {
+		report.SetFontPath(o.fontPath)
+	}
+	if o.fontFS != nil {
+		report.SetFontFS(o.fontFS)
+	}
+	if o.fontCacheDir != "" {
+		report.SetFontCacheDir(o.fontCacheDir)
+	}
+	if o.grid != nil {
+		g := o.grid
+		report.Grid.CustomWidth = g.CustomWidth
+		report.Grid.CustomHeight = g.CustomHeight
+		report.Grid.Margins = g.Margins
+		report.Grid.BaselineGrid = g.BaselineGrid
+		report.Grid.BaselineOffset = g.BaselineOffset
+		err := report.SetGrid(
+			g.Orientation,
+			g.PageSize,
+			g.Unit,
+			g.Margin,
+			g.ColumnCount,
+			g.GutterWidth,
+			g.LineHeight,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := report.Pdf.Error(); err != nil {
+			return nil, fmt.Errorf("Could not create PDF: %v", err)
+		}
+	}
+	return report, nil
 }
 
 // Place a string based on the x, y coordinates on the grid, using the named
 // block and style specifications. Returns the # of lines (different from
 // Block.Height) taken up by this call to help dynamically place following
-// content.
+// content. This is the same as the LineCount from Measure(). The first page is
+// added if there is none yet.
 func (r *Report) Content(
 	x int,
 	y int,
 	blockName string,
 	styleName string,
 	content string,
+	overrides ...StyleOption,
 ) (lineCount int, err error) {
-	var block Block
-	var style Style
-	var ok bool
+	block, style, err := r.lookup(blockName, styleName, overrides...)
+	if err != nil {
+		return 0, err
+	}
+	if err = r.checkGlyphs(style, content); err != nil {
+		return 0, err
+	}
+	r.startPage()
+	return r.place(x, y, block, style, content, blockName)
+}
 
-	lineCount = 0
+// place does the work of Content() once the block and style are resolved. The
+// label is shown on the outline drawn in debug mode.
+func (r *Report) place(x, y int, block Block, style Style, content, label string) (lineCount int, err error) {
+	point := r.Grid.GetPoint(x, y)
+	cell := r.Grid.GetCell(block)
 
-	if block, ok = r.Blocks[blockName]; ok == false {
-		err = fmt.Errorf("Could not find block name in Report: %s", blockName)
-		return lineCount, err
+	// the lines are wrapped here and each fits the cell, so MultiCell only
+	// breaks them where wrap() did and the count is exact
+	lines, err := r.wrap(block, style, content)
+	if err != nil {
+		return 0, err
+	}
+	restore := r.saveDrawing()
+	r.Pdf.SetTextColor(style.TextColor.R, style.TextColor.G, style.TextColor.B)
+	if style.Fill {
+		r.Pdf.SetFillColor(style.FillColor.R, style.FillColor.G, style.FillColor.B)
+	}
+	if style.Border != 0 {
+		r.Pdf.SetDrawColor(style.BorderColor.R, style.BorderColor.G, style.BorderColor.B)
+		if style.BorderWidth > 0 {
+			r.Pdf.SetLineWidth(style.BorderWidth)
+		}
 	}
-	if style, ok = r.Styles[styleName]; ok == false {
-		err = fmt.Errorf("Could not find style name in Report: %s", styleName)
-		return lineCount, err
+	if r.Grid.BaselineGrid {
+		r.placeOnBaselines(point, cell, style, lines)
+	} else {
+		r.Pdf.SetXY(point.X, point.Y)
+		r.Pdf.MultiCell(
+			cell.Width,
+			cell.Height,
+			strings.Join(lines, "\n"),
+			style.convertBorder(),
+			style.convertAlignment(),
+			style.Fill,
+		)
 	}
+	restore()
+	r.outline(x, y, block, len(lines), label)
 
-	point := r.Grid.GetPoint(x, y)
+	return len(lines) * block.Height, nil
+}
+
+// Measurement is the space content takes up when placed, from Measure().
+type Measurement struct {
+	Lines     []string
+	LineCount int
+	Height    float64
+}
+
+// Measure wraps text the way Content() would place it in the named block and
+// style, without drawing anything. Lines are the wrapped lines of text,
+// LineCount is the # of grid lines taken up, the same as Content() returns,
+// and Height is LineCount in the Grid.Unit system.
+func (r *Report) Measure(
+	blockName string,
+	styleName string,
+	text string,
+	overrides ...StyleOption,
+) (Measurement, error) {
+	block, style, err := r.lookup(blockName, styleName, overrides...)
+	if err != nil {
+		return Measurement{}, err
+	}
+	if err = r.checkGlyphs(style, text); err != nil {
+		return Measurement{}, err
+	}
+	lines, err := r.wrap(block, style, text)
+	if err != nil {
+		return Measurement{}, err
+	}
+	m := Measurement{Lines: lines}
+	m.LineCount = len(m.Lines) * block.Height
+	m.Height = float64(m.LineCount) * r.Grid.LineHeight
+	return m, nil
+}
+
+// wrap splits content into the lines it takes up when placed in the block with
+// the style, without drawing anything. Returns the Pdf error when the font of
+// the style could not be set.
+func (r *Report) wrap(block Block, style Style, content string) ([]string, error) {
 	cell := r.Grid.GetCell(block)
+	if err := r.setFont(style); err != nil {
+		return nil, err
+	}
+	r.Pdf.SetCellMargin(r.padding(style))
+	if _, ok := r.utf8Fonts[fontKey(style.FontFamily, style.FontStyle)]; ok {
+		return r.Pdf.SplitText(content, cell.Width), nil
+	}
+	byteLines := r.Pdf.SplitLines([]byte(content), cell.Width)
+	lines := make([]string, len(byteLines))
+	for i, line := range byteLines {
+		lines[i] = string(line)
+	}
+	return lines, nil
+}
 
+// setFont sets the font of the style on Pdf. Once Pdf has an error, such as
+// from an unknown font family, it keeps no current font to measure text with,
+// so the error is returned instead.
+func (r *Report) setFont(style Style) error {
+	if err := r.Pdf.Error(); err != nil {
+		return err
+	}
 	r.Pdf.SetFont(style.FontFamily, style.FontStyle, style.FontSize)
-	r.Pdf.SetXY(point.X, point.Y)
-	r.Pdf.MultiCell(cell.Width, cell.Height, content, "", style.convertAlignment(), false)
+	return r.Pdf.Error()
+}
+
+// padding is the cell margin used for the style.
+func (r *Report) padding(style Style) float64 {
+	if style.Padding > 0 {
+		return style.Padding
+	}
+	return r.cellMargin
+}
 
-	contentLines := strings.Split(content, "\n")
-	for _, line := range contentLines {
-		stringWidth := r.Pdf.GetStringWidth(line)
-		lineCount += int(math.Ceil(stringWidth / cell.Width))
+// startPage adds the first page if none has been added yet, keeping a cursor
+// that was set before it.
+func (r *Report) startPage() {
+	if r.Pdf.PageNo() == 0 {
+		cursor := r.Cursor
+		r.AddPage()
+		r.Cursor = cursor
 	}
-	lineCount *= block.Height
-	return lineCount, nil
+}
+
+// lookup finds the named block and style specifications in the Report, with
+// the overrides applied to the style.
+func (r *Report) lookup(
+	blockName string,
+	styleName string,
+	overrides ...StyleOption,
+) (Block, Style, error) {
+	block, ok := r.Blocks[blockName]
+	if !ok {
+		return block, Style{}, fmt.Errorf("Could not find block name in Report: %s", blockName)
+	}
+	style, err := r.Style(styleName, overrides...)
+	return block, style, err
 }
 
 // AddPage creates new page in the report. The previous page is now set if it
-// exists, and all placement will take place in this new page.
+// exists, and all placement will take place in this new page. The header and
+// footer are placed and the flow cursor moves back to the top line. The page
+// has the orientation and size of the last AddPageWith(), or of the grid.
 func (r *Report) AddPage() {
-	r.Pdf.AddPage()
+	if r.pageGrid != nil {
+		// Within() lays out on a sub grid but the page is set up on its grid
+		layout, page := r.Grid, r.pageGrid
+		r.Grid, r.pageGrid = *page, nil
+		r.AddPage()
+		*page, r.pageGrid = r.Grid, page
+		r.Grid = layout
+		r.Grid.Page = page.Page
+		return
+	}
+	r.Pdf.AddPageFormat(r.Grid.convertOrientation(), r.Grid.pageDimensions())
+	r.Grid.Page = r.Pdf.PageNo()
+	r.setPdfMargins()
+	if r.debug {
+		r.DrawGrid()
+	}
+	r.decoratePage()
+	r.Cursor.Y = 1
+}
+
+// SetMargins sets separate top, right, bottom and left margins in place of
+// Grid.Margin, or mirrored inner and outer margins for facing pages. The
+// columns are recalculated, so it is usually called before any content is
+// placed. For example, a booklet with a wider margin by the binding:
+//
+//   r.SetMargins(Margins{Top: 54, Right: 36, Bottom: 54, Left: 72, Mirrored: true})
+func (r *Report) SetMargins(margins Margins) error {
+	if err := margins.validate(); err != nil {
+		return err
+	}
+	g := r.Grid
+	g.Margins = margins
+	if err := g.fit(); err != nil {
+		return err
+	}
+	r.Grid = g
+	if r.Pdf != nil {
+		r.setPdfMargins()
+	}
+	return nil
+}
+
+// setPdfMargins sets the margins of Pdf to those of the current page.
+func (r *Report) setPdfMargins() {
+	top, right, bottom, left := r.Grid.pageMargins()
+	r.Pdf.SetMargins(left, top, right)
+	r.Pdf.SetAutoPageBreak(false, bottom)
+}
+
+// AddPageWith creates a new page like AddPage() with a different orientation
+// and page size, which later pages keep until changed again. The grid is
+// recalculated for the page: the column count, gutter width, margin and line
+// height stay the same while the column width and line count change. For
+// example, to switch to landscape for a wide table:
+//
+//   r.AddPageWith(OrientationLandscape, PageSizeLetter)
+//   r.FlowTable(wide)
+//
+// PageSizeCustom uses Grid.CustomWidth and Grid.CustomHeight.
+func (r *Report) AddPageWith(orientation, pageSize int) error {
+	if r.pageGrid != nil {
+		return errors.New("Page size cannot be changed within a sub grid")
+	}
+	g := r.Grid
+	g.Orientation = orientation
+	g.PageSize = pageSize
+	if err := g.validate(); err != nil {
+		return err
+	}
+	if err := g.validatePageDimensions(); err != nil {
+		return err
+	}
+	if err := g.fit(); err != nil {
+		return err
+	}
+	r.Grid = g
+	r.AddPage()
+	return nil
 }
 
 // AddStyle adds a new style to use when placing content in this report.
 //
-// All specs are set. So even small differences will require different styles.
-// An example set of styles can look like the following:
+// All specs are set. Use AddStyleFrom() for styles with small differences, or
+// StyleOption overrides when placing content. An example set of styles can
+// look like the following:
 //
 //   r.AddStyle("header", "OpenSans", "", 24, AlignmentCenter | AlignmentTop)
 //   r.AddStyle("subheader", "OpenSans", "", 18, AlignmentLeft | AlignmentTop)
@@ -91,6 +387,7 @@ func (r *Report) AddStyle(
 	fontSize float64,
 	alignment int,
 ) {
+	delete(r.derivedStyles, name)
 	r.Styles[name] = Style{
 		FontFamily: fontFamily,
 		FontStyle:  fontStyle,
@@ -141,38 +438,100 @@ func (r *Report) AddBlock(name string, width, height int) {
 // 	 koi8-r
 // 	 koi8-u
 func (r *Report) AddFont(filename, encoding string) error {
-	var err error
-	err = r.PrepareFontCompiledPath()
+	if r.Pdf == nil {
+		return errors.New("Could not add font: grid has not been set")
+	}
+	definition, data, err := r.loadFont(filename, encoding)
 	if err != nil {
 		return err
 	}
+	familyName := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	r.Pdf.AddFontFromBytes(familyName, "", definition, data)
+	if err = r.Pdf.Error(); err != nil {
+		return fmt.Errorf("Could not add font %s: %v", filename, err)
+	}
+	return nil
+}
 
-	ext := path.Ext(filename)
-	familyName := filename[:len(filename)-len(ext)]
+// AddUTF8Font adds a TrueType font that accepts any UTF-8 text, unlike the
+// single-byte encodings of AddFont(). The family and style are the names used
+// with Report.AddStyle(), so each style of a family (such as "B" for bold) is
+// added separately. A relative ttfPath is found in Report.FontSourcePath. Only
+// the glyphs used are embedded in the PDF.
+//
+//   r.AddUTF8Font("NotoSans", "", "NotoSans-Regular.ttf")
+//   r.AddUTF8Font("NotoSans", "B", "NotoSans-Bold.ttf")
+//   r.AddStyle("body", "NotoSans", "", 10, AlignLeft|AlignTop)
+//
+// Placing content with a character the font has no glyph for returns an error.
+func (r *Report) AddUTF8Font(family, style, ttfPath string) error {
+	if r.Pdf == nil {
+		return errors.New("Could not add font: grid has not been set")
+	}
+	var data []byte
+	var err error
+	if path.IsAbs(ttfPath) {
+		data, err = ioutil.ReadFile(ttfPath)
+	} else {
+		data, err = r.readFontSource(ttfPath)
+	}
+	if err != nil {
+		return fmt.Errorf("Could not read font file: %v", err)
+	}
+	font, err := sfnt.Parse(data)
+	if err != nil {
+		return fmt.Errorf("Could not parse TrueType font %s: %v", ttfPath, err)
+	}
+	r.Pdf.AddUTF8FontFromBytes(family, style, data)
+	if err = r.Pdf.Error(); err != nil {
+		return fmt.Errorf("Could not add font %s: %v", ttfPath, err)
+	}
+	r.utf8Fonts[fontKey(family, style)] = font
+	return nil
+}
 
-	// auto compiles
-	if path.Ext(filename) == ".json" {
-		if r.IsCompiledFile(filename) {
-			r.Pdf.AddFont(familyName, "", filename)
-		} else {
-			return fmt.Errorf("Cache font file not found: %s", filename)
+// checkGlyphs makes sure a UTF-8 font has a glyph for every character in
+// content. Fonts added with AddFont() are not checked.
+func (r *Report) checkGlyphs(style Style, content string) error {
+	font, ok := r.utf8Fonts[fontKey(style.FontFamily, style.FontStyle)]
+	if !ok {
+		return nil
+	}
+	var buf sfnt.Buffer
+	for _, c := range content {
+		if unicode.IsControl(c) {
+			continue
 		}
-	} else {
-		if r.IsSourcedFont(filename) {
-			compiledFilename, err := r.CompileFont(filename, encoding)
-			if err != nil {
-				return fmt.Errorf("Could not compile font: %v", err)
-			}
-			r.Pdf.AddFont(familyName, "", compiledFilename)
-		} else {
-			return fmt.Errorf("Source font file not found: %s", filename)
+		// gofpdf only keeps widths for the basic multilingual plane
+		index, err := font.GlyphIndex(&buf, c)
+		if err != nil || index == 0 || c > 0xFFFF {
+			return fmt.Errorf(
+				"Font %s has no glyph for %q (U+%04X)",
+				style.FontFamily,
+				c,
+				c,
+			)
 		}
 	}
 	return nil
 }
 
+// fontKey identifies a font family and style the same way regardless of case
+// and style order. Underline and strikeout do not change the font.
+func fontKey(family, style string) string {
+	style = strings.ToUpper(style)
+	style = strings.NewReplacer("U", "", "S", "").Replace(style)
+	if style == "IB" {
+		style = "BI"
+	}
+	return strings.ToLower(family) + style
+}
+
 // PrepareFontCompiledPath creates the "_compiled" subdirectory.
 func (r *Report) PrepareFontCompiledPath() error {
+	if r.FontCompiledPath == "" {
+		return nil
+	}
 	if _, err := os.Stat(path.Join(r.FontCompiledPath)); os.IsNotExist(err) {
 		err = os.MkdirAll(r.FontCompiledPath, os.ModeDir)
 		if err != nil {
@@ -184,45 +543,31 @@ func (r *Report) PrepareFontCompiledPath() error {
 }
 
 // CompileEncoding creates the encoding map file in Report.FontCompiledPath so
-// the underlying Fpdf object can correctly use it to compile fonts.
+// the underlying Fpdf object can correctly use it to compile fonts. The file is
+// written to a temporary file and renamed so it is never read partly written.
 func (r *Report) CompileEncoding(encoding string) (filename string, err error) {
-	filename = path.Join(r.FontCompiledPath, encoding+".map")
-	if r.IsCompiledFile(filename) {
-		return
-	}
-	if data, ok := encodings[encoding]; ok {
-		file, err := os.Create(filename)
-		if err != nil {
-			err = fmt.Errorf("Could not open file to compile encoding file: %v", err)
-			return filename, err
-		}
-		defer file.Close()
-		reader := strings.NewReader(data)
-		decoder := base64.NewDecoder(base64.StdEncoding, reader)
-		_, err = io.Copy(file, decoder)
-		if err != nil {
-			err = fmt.Errorf("Encoding failed to copy to file: %v", err)
-			return filename, err
-		}
-	} else {
-		err = fmt.Errorf("Encoding not supported: %s", encoding)
-	}
-	return
+	return compileEncoding(r.FontCompiledPath, encoding)
 }
 
-// CompileFont takes a font file in Report.FontSourcePath and converts it into
-// .json format if it doesn't exist in Report.FontCompiledPath.
+// CompileFont takes a font file in Report.FontSourcePath, or the fs.FS of
+// Report.SetFontFS(), and converts it into .json format if it doesn't exist in
+// Report.FontCompiledPath. The compiled filename has the encoding and a hash
+// of the font file, such as "OpenSans-Bold-cp1252-1f2e3d4c5b6a7980.json", so a
+// changed font file is compiled again. Compiling is safe from many goroutines
+// and processes at once.
 func (r *Report) CompileFont(filename, encoding string) (string, error) {
-	fontFilename := path.Join(r.FontSourcePath, filename)
-	// replacing ext with json
-	extLen := len(path.Ext(filename))
-	compiledFilename := filename[:len(filename)-extLen] + ".json"
-	encodingFilename, err := r.CompileEncoding(encoding)
+	if r.FontCompiledPath == "" {
+		return "", errors.New("Could not compile font: no font cache directory")
+	}
+	source, err := r.readFontSource(filename)
 	if err != nil {
-		return compiledFilename, err
+		return "", fmt.Errorf("Could not read font file: %v", err)
+	}
+	key := fontCacheKey(filename, encoding, source)
+	if err = r.PrepareFontCompiledPath(); err != nil {
+		return key + ".json", err
 	}
-	err = gofpdf.MakeFont(fontFilename, encodingFilename, r.FontCompiledPath, nil, true)
-	return compiledFilename, err
+	return key + ".json", compileFont(r.FontCompiledPath, key, filename, encoding, source)
 }
 
 // IsCompiledFile checks if the filename exists in Report.FontCompiledPath. This
@@ -233,14 +578,24 @@ func (r *Report) IsCompiledFile(filename string) bool {
 	return !os.IsNotExist(err)
 }
 
-// IsSourcedFont checks if the font filename exists in Report.FontSourcePath.
+// IsSourcedFont checks if the font filename exists in Report.FontSourcePath,
+// or the fs.FS of Report.SetFontFS().
 func (r *Report) IsSourcedFont(filename string) bool {
+	if r.fontFS != nil {
+		_, err := fs.Stat(r.fontFS, filename)
+		return err == nil
+	}
 	_, err := os.Stat(path.Join(r.FontSourcePath, filename))
 	return !os.IsNotExist(err)
 }
 
 // SetGrid sets all page and grid related specifications required to place
-// content. This must be set before any Content() calls are made.
+// content. This must be set before any Content() calls are made. Automatic
+// page breaks in Pdf are turned off since Flow() handles them on the grid.
+// PageSizeCustom uses the Grid.CustomWidth and Grid.CustomHeight already set.
+// An error is returned, and the Report left as it was, when the specifications
+// are invalid, those are not set or the grid does not fit between the margins
+// of the page.
 func (r *Report) SetGrid(
 	orientation int,
 	pageSize int,
@@ -249,36 +604,123 @@ func (r *Report) SetGrid(
 	columnCount int,
 	gutterWidth float64,
 	lineHeight float64,
-) {
-	fontPath := r.FontSourcePath
-	r.Grid = Grid{
-		Orientation: orientation,
-		PageSize:    pageSize,
-		Unit:        unit,
-		ColumnCount: columnCount,
-		GutterWidth: gutterWidth,
-		Margin:      margin,
-		LineHeight:  lineHeight,
-	}
-
-	pdf := gofpdf.New(
-		r.Grid.convertOrientation(),
-		r.Grid.convertUnit(),
-		r.Grid.convertPageSize(),
-		fontPath,
-	)
+) error {
+	fontPath := r.FontCompiledPath
+	g := Grid{
+		Orientation:  orientation,
+		PageSize:     pageSize,
+		Unit:         unit,
+		ColumnCount:  columnCount,
+		GutterWidth:  gutterWidth,
+		Margin:       margin,
+		LineHeight:   lineHeight,
+		CustomWidth:  r.Grid.CustomWidth,
+		CustomHeight: r.Grid.CustomHeight,
+		Margins:      r.Grid.Margins,
+
+		BaselineGrid:   r.Grid.BaselineGrid,
+		BaselineOffset: r.Grid.BaselineOffset,
+	}
+	if err := g.validate(); err != nil {
+		return err
+	}
+	if err := g.validatePageDimensions(); err != nil {
+		return err
+	}
+	if err := g.fit(); err != nil {
+		return err
+	}
+
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: g.convertOrientation(),
+		UnitStr:        g.convertUnit(),
+		Size:           g.pageDimensions(),
+		FontDirStr:     fontPath,
+	})
+	r.Grid = g
 	r.Pdf = pdf
-	r.Pdf.SetMargins(margin, margin, margin)
-	pageWidth, pageHeight := r.Pdf.GetPageSize()
-	r.Grid.PageWidth = pageWidth
-	r.Grid.PageHeight = pageHeight
-	r.Grid.CalculateColumns()
+	r.setPdfMargins()
+	r.cellMargin = r.Pdf.GetCellMargin()
+	r.Pdf.AliasNbPages(tokenPages)
+	return nil
 }
 
 // SetFontPath tells the Report where to find fonts specified with AddFont().
+// It can be called before or after SetGrid().
 func (r *Report) SetFontPath(fontSourcePath string) {
+	r.fontFS = nil
 	r.FontSourcePath = fontSourcePath
 	r.FontCompiledPath = path.Join(fontSourcePath, "_compiled")
 	r.PrepareFontCompiledPath()
-	r.Pdf.SetFontLocation(r.FontCompiledPath)
+	if r.Pdf != nil {
+		r.Pdf.SetFontLocation(r.FontCompiledPath)
+	}
+}
+
+// WriteTo closes the PDF document and writes it to w, returning the number of
+// bytes written. Any error accumulated while placing content is returned as an
+// *OutputError. The Report is finished afterwards and no more content can be
+// placed.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	if err := r.checkOutput("write"); err != nil {
+		return 0, err
+	}
+	cw := &countingWriter{w: w}
+	if err := r.Pdf.Output(cw); err != nil {
+		return cw.n, &OutputError{Op: "write", Err: err}
+	}
+	return cw.n, nil
+}
+
+// Save closes the PDF document and writes it to the file at filename, creating
+// or replacing it. The document is written to a temporary file in the same
+// directory that is renamed once complete, so the file is not touched if the
+// document has an error or writing fails partway.
+func (r *Report) Save(filename string) error {
+	if err := r.checkOutput("save"); err != nil {
+		return err
+	}
+	err := writeAtomic(filename, func(w io.Writer) error {
+		_, err := r.WriteTo(w)
+		return errors.Unwrap(err)
+	})
+	if err != nil {
+		return &OutputError{Op: "save", Err: err}
+	}
+	return nil
+}
+
+// Bytes closes the PDF document and returns its contents.
+func (r *Report) Bytes() ([]byte, error) {
+	if err := r.checkOutput("output"); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		return nil, &OutputError{Op: "output", Err: errors.Unwrap(err)}
+	}
+	return buf.Bytes(), nil
+}
+
+// checkOutput makes sure there is a Pdf without errors to write out.
+func (r *Report) checkOutput(op string) error {
+	if r.Pdf == nil {
+		return &OutputError{Op: op, Err: errors.New("Grid has not been set")}
+	}
+	if err := r.Pdf.Error(); err != nil {
+		return &OutputError{Op: op, Err: err}
+	}
+	return nil
+}
+
+// countingWriter keeps track of the bytes written for Report.WriteTo.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
 }
@@ -1,11 +1,17 @@
 package tps
 
 import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func TestAddBlock(t *testing.T) {
-	r := NewReport()
+	r, _ := NewReport()
 	r.AddBlock("test", 1, 2)
 	e := Block{1, 2}
 	if b := r.Blocks["test"]; b != e {
@@ -26,29 +32,29 @@ func TestAddBlock(t *testing.T) {
 }
 
 func TestAddStyle(t *testing.T) {
-	r := NewReport()
+	r, _ := NewReport()
 	a := AlignLeft | AlignTop
 	r.AddStyle("test", "foo", "", 12, a)
-	e := Style{"foo", "", 12, a}
+	e := Style{FontFamily: "foo", FontSize: 12, Alignment: a}
 	if s := r.Styles["test"]; s != e {
 		t.Errorf("AddStyle did not store style correctly. Got %v expected %v", s, e)
 	}
 
 	r.AddStyle("test", "foo bar", "", 24, a)
-	e = Style{"foo bar", "", 24, a}
+	e = Style{FontFamily: "foo bar", FontSize: 24, Alignment: a}
 	if s := r.Styles["test"]; s != e {
 		t.Errorf("AddStyle did not overwrite style correctly. Got %v expected %v", s, e)
 	}
 
 	r.AddStyle("new test", "foo bar", "", 24, a)
-	e = Style{"foo bar", "", 24, a}
+	e = Style{FontFamily: "foo bar", FontSize: 24, Alignment: a}
 	if s := r.Styles["new test"]; s != e {
 		t.Errorf("AddStyle did not store style correctly. Got %v expected %v", s, e)
 	}
 }
 
 func TestSetGrid(t *testing.T) {
-	r := NewReport()
+	r, _ := NewReport()
 	r.SetGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36.0, 12, 12.0, 12.0)
 	g := r.Grid
 	e := Grid{
@@ -71,3 +77,223 @@ func TestSetGrid(t *testing.T) {
 		t.Errorf("SetGrid did not initialize Pdf")
 	}
 }
+
+func newTestReport() *Report {
+	r, _ := NewReport()
+	r.SetGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36.0, 12, 12.0, 12.0)
+	return r
+}
+
+func TestBytes(t *testing.T) {
+	r := newTestReport()
+	b, err := r.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes returned an error: %v", err)
+	}
+	if !bytes.HasPrefix(b, []byte("%PDF-")) {
+		t.Errorf("Bytes did not return a PDF document. Got prefix %q", b[:8])
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	r := newTestReport()
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo returned an error: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned wrong byte count. Got %d expected %d", n, buf.Len())
+	}
+}
+
+func TestSave(t *testing.T) {
+	r := newTestReport()
+	filename := filepath.Join(t.TempDir(), "test.pdf")
+	if err := r.Save(filename); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	if info, err := os.Stat(filename); err != nil || info.Size() == 0 {
+		t.Errorf("Save did not write the PDF file: %v", err)
+	}
+}
+
+func TestSavePermissions(t *testing.T) {
+	r := newTestReport()
+	dir := t.TempDir()
+
+	// a new file gets the permissions os.Create() gives, 0666 less the umask
+	created := filepath.Join(dir, "created")
+	file, err := os.Create(created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	filename := filepath.Join(dir, "new.pdf")
+	if err = r.Save(filename); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	expected, _ := os.Stat(created)
+	if info, _ := os.Stat(filename); info.Mode() != expected.Mode() {
+		t.Errorf("Save did not use the umask for a new file. Got %v expected %v", info.Mode(), expected.Mode())
+	}
+
+	// a replaced file keeps its permissions
+	filename = filepath.Join(dir, "old.pdf")
+	if err = ioutil.WriteFile(filename, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Chmod(filename, 0640)
+	if err = r.Save(filename); err != nil {
+		t.Fatalf("Save returned an error: %v", err)
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0640 {
+		t.Errorf("Save did not keep the permissions of the replaced file. Got %v", info.Mode())
+	}
+}
+
+func TestOutputErrors(t *testing.T) {
+	var outputErr *OutputError
+
+	r, _ := NewReport()
+	if _, err := r.Bytes(); !errors.As(err, &outputErr) {
+		t.Errorf("Bytes without a grid did not return an OutputError. Got %v", err)
+	}
+
+	r = newTestReport()
+	pdfErr := errors.New("pdf error")
+	r.Pdf.SetError(pdfErr)
+	filename := filepath.Join(t.TempDir(), "test.pdf")
+	err := r.Save(filename)
+	if !errors.As(err, &outputErr) || !errors.Is(err, pdfErr) {
+		t.Errorf("Save did not return the Pdf error as an OutputError. Got %v", err)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("Save created a file for a document with an error")
+	}
+}
+
+func TestSaveFailsPartway(t *testing.T) {
+	r := newTestReport()
+	r.AddPage()
+	outputErr := errors.New("output error")
+	// the footer runs while the document is written out
+	r.Pdf.SetFooterFunc(func() { r.Pdf.SetError(outputErr) })
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "test.pdf")
+	if err := ioutil.WriteFile(filename, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Save(filename); !errors.Is(err, outputErr) {
+		t.Errorf("Save did not return the output error. Got %v", err)
+	}
+	if data, _ := ioutil.ReadFile(filename); string(data) != "old" {
+		t.Errorf("Save replaced the file after failing. Got %q", data)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("Save left a temporary file after failing. Got %d files", len(files))
+	}
+}
+
+func TestContentFirstPage(t *testing.T) {
+	r := newTestReport()
+	r.AddStyle("body", "Helvetica", "", 10, AlignLeft)
+	r.AddBlock("full", 12, 1)
+	if _, err := r.Content(1, 1, "full", "body", "Total"); err != nil {
+		t.Fatalf("Content returned an error: %v", err)
+	}
+	if _, err := r.RichContent(1, 2, "full", "body", "<b>Total</b>"); err != nil {
+		t.Fatalf("RichContent returned an error: %v", err)
+	}
+	if r.Pdf.PageNo() != 1 {
+		t.Errorf("Content did not add the first page. Got %d pages", r.Pdf.PageNo())
+	}
+	if b, _ := r.Bytes(); !bytes.HasPrefix(b, []byte("%PDF-")) {
+		t.Errorf("Content placed text outside of a page. Got prefix %q", b[:8])
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	r := newTestReport()
+	r.AddStyle("body", "Helvetica", "", 10, AlignLeft)
+	r.AddBlock("narrow", 1, 2)
+	r.AddPage()
+
+	// one column is 34pt wide, so the long word is broken across lines
+	text := "aa bb   \nsupercalifragilistic"
+	m, err := r.Measure("narrow", "body", text)
+	if err != nil {
+		t.Fatalf("Measure returned an error: %v", err)
+	}
+	if len(m.Lines) < 3 || !strings.HasSuffix(strings.Join(m.Lines, ""), "supercalifragilistic") {
+		t.Errorf("Measure did not wrap lines correctly. Got %q", m.Lines)
+	}
+	if m.LineCount != len(m.Lines)*2 {
+		t.Errorf("Measure returned wrong LineCount. Got %d expected %d", m.LineCount, len(m.Lines)*2)
+	}
+	if m.Height != float64(m.LineCount)*12.0 {
+		t.Errorf("Measure returned wrong Height. Got %.1f expected %.1f", m.Height, float64(m.LineCount)*12.0)
+	}
+
+	lineCount, err := r.Content(1, 1, "narrow", "body", text)
+	if err != nil {
+		t.Fatalf("Content returned an error: %v", err)
+	}
+	if lineCount != m.LineCount {
+		t.Errorf("Content and Measure disagree. Got %d expected %d", lineCount, m.LineCount)
+	}
+
+	if _, err = r.Measure("missing", "body", text); err == nil {
+		t.Errorf("Measure did not return an error for an unknown block")
+	}
+}
+
+func TestContentStyleColors(t *testing.T) {
+	r := newTestReport()
+	r.Styles["total"] = Style{
+		FontFamily:  "Helvetica",
+		FontSize:    10,
+		TextColor:   Color{31, 119, 180},
+		FillColor:   Color{240, 240, 240},
+		Fill:        true,
+		Border:      BorderTop | BorderBottom,
+		BorderWidth: 1,
+		Padding:     4,
+	}
+	r.AddBlock("full", 12, 1)
+	r.AddPage()
+	r.Pdf.SetTextColor(1, 2, 3)
+	if _, err := r.Content(1, 1, "full", "total", "Total"); err != nil {
+		t.Fatalf("Content returned an error: %v", err)
+	}
+	if red, green, blue := r.Pdf.GetTextColor(); red != 1 || green != 2 || blue != 3 {
+		t.Errorf("Content did not restore text color. Got %d %d %d", red, green, blue)
+	}
+	if _, err := r.Bytes(); err != nil {
+		t.Errorf("Bytes returned an error with style colors: %v", err)
+	}
+}
+
+func TestUnknownFontFamily(t *testing.T) {
+	r := newTestReport()
+	r.AddStyle("missing", "Missing", "", 10, AlignLeft)
+	r.AddBlock("full", 12, 1)
+	r.AddPage()
+	if _, err := r.Content(1, 1, "full", "missing", "Total"); err == nil {
+		t.Error("Content did not return an error for an unknown font family")
+	}
+	// the error stays on Pdf, so text in any font can no longer be measured
+	r.AddStyle("body", "Helvetica", "", 10, AlignLeft)
+	if _, err := r.Measure("full", "body", "Total"); err == nil {
+		t.Error("Measure did not return the Pdf error")
+	}
+	if _, err := r.Flow("full", "body", "Total"); err == nil {
+		t.Error("Flow did not return the Pdf error")
+	}
+	if _, err := r.RichContent(1, 1, "full", "body", "<b>Total</b>"); err == nil {
+		t.Error("RichContent did not return the Pdf error")
+	}
+	if _, err := r.Bytes(); err == nil {
+		t.Error("Bytes did not return the Pdf error")
+	}
+}
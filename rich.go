@@ -0,0 +1,344 @@
+package tps
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// richRun is a piece of rich content text in a single style.
+type richRun struct {
+	text  string
+	style Style
+}
+
+// richLine is one wrapped line of rich content.
+type richLine struct {
+	runs  []richRun
+	width float64
+}
+
+// richTag matches the inside of a rich content tag such as b or
+// style name="total".
+var richTag = regexp.MustCompile(`^(\w+)(?:\s+(\w+)="([^"]*)")?$`)
+
+var richEntities = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&quot;", `"`, "&amp;", "&")
+
+var richEscapes = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// fontMetrics returns the ascent and descent of the font of the style in the
+// Grid.Unit system. Fonts without metrics, like the standard fonts, use 80%
+// and 20% of the font size, which places text where Fpdf.CellFormat() does.
+func (r *Report) fontMetrics(style Style) (ascent, descent float64) {
+	r.Pdf.SetFont(style.FontFamily, style.FontStyle, style.FontSize)
+	_, size := r.Pdf.GetFontSize()
+	desc := r.Pdf.GetFontDesc("", "")
+	if desc.Ascent > 0 && desc.Descent < 0 {
+		return size * float64(desc.Ascent) / 1000, size * float64(-desc.Descent) / 1000
+	}
+	return size * 0.8, size * 0.2
+}
+
+// escapeRich escapes text for use in rich content markup.
+func escapeRich(value string) string {
+	return richEscapes.Replace(value)
+}
+
+// RichContent places markup like Content(), but the text can change style
+// within the same block using these tags:
+//
+//	<b>bold</b>
+//	<i>italic</i>
+//	<u>underline</u>
+//	<color value="#1f77b4">colored</color>
+//	<style name="total">another named style</style>
+//
+// Tags can be nested, and &lt; &gt; &quot; and &amp; are used for those
+// characters. Text wraps across the styles within the block width, and the
+// alignment, fill and border of the named style apply to the whole block.
+// Returns the # of lines taken up, the same as Content(). The overrides apply
+// to the base style like they do for Content().
+//
+//	r.RichContent(1, 20, "full", "body", `Total: <b>$1,204.00</b>`)
+func (r *Report) RichContent(
+	x int,
+	y int,
+	blockName string,
+	styleName string,
+	markup string,
+	overrides ...StyleOption,
+) (lineCount int, err error) {
+	block, style, err := r.lookup(blockName, styleName, overrides...)
+	if err != nil {
+		return 0, err
+	}
+	lines, err := r.layoutRichContent(block, style, markup)
+	if err != nil {
+		return 0, err
+	}
+	if len(lines) > 0 {
+		r.startPage()
+		r.drawRich(x, y, block, style, lines, blockName)
+	}
+	return len(lines) * block.Height, nil
+}
+
+// layoutRichContent parses markup and wraps it into lines for the block.
+func (r *Report) layoutRichContent(block Block, style Style, markup string) ([]richLine, error) {
+	runs, err := r.parseRich(style, markup)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if err = r.checkGlyphs(run.style, run.text); err != nil {
+			return nil, err
+		}
+		if err = r.setFont(run.style); err != nil {
+			return nil, err
+		}
+	}
+	cell := r.Grid.GetCell(block)
+	return r.layoutRich(runs, cell.Width-r.padding(style)*2), nil
+}
+
+// drawRich places wrapped lines of rich content at the x, y coordinates.
+func (r *Report) drawRich(x, y int, block Block, style Style, lines []richLine, label string) {
+	point := r.Grid.GetPoint(x, y)
+	cell := r.Grid.GetCell(block)
+	margin := r.padding(style)
+
+	restore := r.saveDrawing()
+	defer restore()
+
+	if style.Fill || style.Border != 0 {
+		if style.Fill {
+			r.Pdf.SetFillColor(style.FillColor.R, style.FillColor.G, style.FillColor.B)
+		}
+		if style.Border != 0 {
+			r.Pdf.SetDrawColor(style.BorderColor.R, style.BorderColor.G, style.BorderColor.B)
+			if style.BorderWidth > 0 {
+				r.Pdf.SetLineWidth(style.BorderWidth)
+			}
+		}
+		r.Pdf.SetXY(point.X, point.Y)
+		height := cell.Height * float64(len(lines))
+		r.Pdf.CellFormat(cell.Width, height, "", style.convertBorder(), 0, "", style.Fill, 0, "")
+	}
+
+	// each run is drawn on the baseline of its line, so runs of different
+	// sizes line up
+	for i, line := range lines {
+		lineX := point.X + margin
+		switch {
+		case style.Alignment&AlignCenter > 0:
+			lineX += (cell.Width - margin*2 - line.width) / 2
+		case style.Alignment&AlignRight > 0:
+			lineX += cell.Width - margin*2 - line.width
+		}
+		ascent, descent := 0.0, 0.0
+		for _, run := range line.runs {
+			runAscent, runDescent := r.fontMetrics(run.style)
+			ascent = math.Max(ascent, runAscent)
+			descent = math.Max(descent, runDescent)
+		}
+		lineY := point.Y + cell.Height*float64(i)
+		var baseline float64
+		switch {
+		case r.Grid.BaselineGrid:
+			baseline = r.Grid.baseline(point.Y, cell.Height, i)
+		case style.Alignment&AlignTop > 0:
+			baseline = lineY + ascent
+		case style.Alignment&AlignBottom > 0:
+			baseline = lineY + cell.Height - descent
+		default:
+			baseline = lineY + (cell.Height-ascent-descent)/2 + ascent
+		}
+		for _, run := range line.runs {
+			r.Pdf.SetFont(run.style.FontFamily, run.style.FontStyle, run.style.FontSize)
+			r.Pdf.SetTextColor(run.style.TextColor.R, run.style.TextColor.G, run.style.TextColor.B)
+			r.Pdf.Text(lineX, baseline, run.text)
+			lineX += r.Pdf.GetStringWidth(run.text)
+		}
+	}
+	r.outline(x, y, block, len(lines), label)
+}
+
+// parseRich splits markup into runs of text with the tags applied to the base
+// style.
+func (r *Report) parseRich(base Style, markup string) ([]richRun, error) {
+	var runs []richRun
+	styles := []Style{base}
+	var tags []string
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			runs = append(runs, richRun{richEntities.Replace(text.String()), styles[len(styles)-1]})
+			text.Reset()
+		}
+	}
+
+	for len(markup) > 0 {
+		start := strings.IndexByte(markup, '<')
+		if start < 0 {
+			text.WriteString(markup)
+			break
+		}
+		text.WriteString(markup[:start])
+		end := strings.IndexByte(markup[start:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("Rich content tag is not closed: %s", markup[start:])
+		}
+		tag := markup[start+1 : start+end]
+		markup = markup[start+end+1:]
+		flush()
+
+		if strings.HasPrefix(tag, "/") {
+			name := tag[1:]
+			if len(tags) == 0 || tags[len(tags)-1] != name {
+				return nil, fmt.Errorf("Rich content closing tag does not match: </%s>", name)
+			}
+			tags = tags[:len(tags)-1]
+			styles = styles[:len(styles)-1]
+			continue
+		}
+
+		match := richTag.FindStringSubmatch(tag)
+		if match == nil {
+			return nil, fmt.Errorf("Invalid rich content tag: <%s>", tag)
+		}
+		name, attribute, value := match[1], match[2], match[3]
+		style := styles[len(styles)-1]
+		switch {
+		case name == "b" && attribute == "":
+			StyleBold()(&style)
+		case name == "i" && attribute == "":
+			StyleItalic()(&style)
+		case name == "u" && attribute == "":
+			StyleUnderline()(&style)
+		case name == "color" && attribute == "value":
+			color, err := ParseColor(value)
+			if err != nil {
+				return nil, err
+			}
+			style.TextColor = color
+		case name == "style" && attribute == "name":
+			named, err := r.Style(value)
+			if err != nil {
+				return nil, err
+			}
+			style = named
+		default:
+			return nil, fmt.Errorf("Unknown rich content tag: <%s>", tag)
+		}
+		tags = append(tags, name)
+		styles = append(styles, style)
+	}
+	flush()
+	if len(tags) > 0 {
+		return nil, fmt.Errorf("Rich content tag is not closed: <%s>", tags[len(tags)-1])
+	}
+	return runs, nil
+}
+
+// layoutRich wraps runs into lines no wider than width, breaking at spaces
+// where possible and within words that do not fit on a line by themselves.
+func (r *Report) layoutRich(runs []richRun, width float64) []richLine {
+	var lines []richLine
+	var line richLine
+	var word []richRun
+	var space *richRun
+
+	measure := func(run richRun) float64 {
+		r.Pdf.SetFont(run.style.FontFamily, run.style.FontStyle, run.style.FontSize)
+		return r.Pdf.GetStringWidth(run.text)
+	}
+	appendRun := func(run richRun) {
+		n := len(line.runs)
+		if n > 0 && line.runs[n-1].style == run.style {
+			line.runs[n-1].text += run.text
+		} else {
+			line.runs = append(line.runs, run)
+		}
+		line.width += measure(run)
+	}
+	newLine := func() {
+		lines = append(lines, line)
+		line = richLine{}
+	}
+	placeWord := func() {
+		if len(word) == 0 {
+			return
+		}
+		wordWidth := 0.0
+		for _, run := range word {
+			wordWidth += measure(run)
+		}
+		spaceWidth := 0.0
+		if space != nil && len(line.runs) > 0 {
+			spaceWidth = measure(*space)
+		}
+		if len(line.runs) > 0 && line.width+spaceWidth+wordWidth > width {
+			newLine()
+		} else if spaceWidth > 0 {
+			appendRun(*space)
+		}
+		if wordWidth <= width || len(line.runs) > 0 {
+			for _, run := range word {
+				appendRun(run)
+			}
+		} else {
+			// the word is too long for any line, so break it anywhere
+			for _, run := range word {
+				for _, c := range run.text {
+					char := richRun{string(c), run.style}
+					if len(line.runs) > 0 && line.width+measure(char) > width {
+						newLine()
+					}
+					appendRun(char)
+				}
+			}
+		}
+		word = nil
+		space = nil
+	}
+
+	for _, run := range runs {
+		token := strings.Builder{}
+		tokenSpace := false
+		flushToken := func() {
+			if token.Len() == 0 {
+				return
+			}
+			if tokenSpace {
+				placeWord()
+				space = &richRun{token.String(), run.style}
+			} else {
+				word = append(word, richRun{token.String(), run.style})
+			}
+			token.Reset()
+		}
+		for _, c := range run.text {
+			switch {
+			case c == '\n':
+				flushToken()
+				placeWord()
+				newLine()
+				space = nil
+			case unicode.IsSpace(c) != tokenSpace:
+				flushToken()
+				tokenSpace = !tokenSpace
+				token.WriteRune(c)
+			default:
+				token.WriteRune(c)
+			}
+		}
+		flushToken()
+	}
+	placeWord()
+	if len(line.runs) > 0 {
+		newLine()
+	}
+	return lines
+}
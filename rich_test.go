@@ -0,0 +1,108 @@
+package tps
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseRich(t *testing.T) {
+	r := newFlowReport(t)
+	r.AddStyle("total", "Courier", "", 12, AlignRight)
+	base, _ := r.Style("body")
+	runs, err := r.parseRich(base, `a <b>b <i>c</i></b> <color value="red">&lt;d&gt;</color><style name="total">e</style>`)
+	if err != nil {
+		t.Fatalf("parseRich returned an error: %v", err)
+	}
+	expected := []struct {
+		text      string
+		fontStyle string
+	}{
+		{"a ", ""},
+		{"b ", "B"},
+		{"c", "BI"},
+		{" ", ""},
+		{"<d>", ""},
+		{"e", ""},
+	}
+	if len(runs) != len(expected) {
+		t.Fatalf("parseRich returned wrong runs. Got %v", runs)
+	}
+	for i, e := range expected {
+		if runs[i].text != e.text || runs[i].style.FontStyle != e.fontStyle {
+			t.Errorf("parseRich returned wrong run %d. Got %q %q expected %q %q",
+				i, runs[i].text, runs[i].style.FontStyle, e.text, e.fontStyle)
+		}
+	}
+	if runs[4].style.TextColor != (Color{255, 0, 0}) {
+		t.Errorf("parseRich did not apply color. Got %v", runs[4].style.TextColor)
+	}
+	if runs[5].style.FontFamily != "Courier" {
+		t.Errorf("parseRich did not apply named style. Got %v", runs[5].style)
+	}
+
+	for _, markup := range []string{"<b>a", "a</b>", "<b>a</i>", "<blink>a</blink>", "<b", `<style name="missing">a</style>`} {
+		if _, err := r.parseRich(base, markup); err == nil {
+			t.Errorf("parseRich did not return an error for %q", markup)
+		}
+	}
+}
+
+func TestRichContent(t *testing.T) {
+	r := newFlowReport(t)
+	r.AddBlock("narrow", 2, 1)
+	r.AddPage()
+
+	lineCount, err := r.RichContent(1, 1, "full", "body", "Total: <b>$1,204.00</b>")
+	if err != nil {
+		t.Fatalf("RichContent returned an error: %v", err)
+	}
+	if lineCount != 1 {
+		t.Errorf("RichContent returned wrong line count. Got %d expected %d", lineCount, 1)
+	}
+
+	markup := strings.Repeat("word <b>bold</b> <i>italic</i> ", 5) + "\n\nend"
+	lineCount, err = r.RichContent(1, 3, "narrow", "body", markup)
+	if err != nil {
+		t.Fatalf("RichContent returned an error: %v", err)
+	}
+	base, _ := r.Style("body")
+	lines, _ := r.layoutRichContent(r.Blocks["narrow"], base, markup)
+	// the text of the narrow block wraps inside its padding
+	width := r.Grid.GetCell(r.Blocks["narrow"]).Width - r.padding(base)*2
+	for _, line := range lines {
+		if line.width > width {
+			t.Errorf("RichContent wrapped a line wider than the block. Got %.1f max %.1f", line.width, width)
+		}
+	}
+	if lineCount != len(lines) {
+		t.Errorf("RichContent returned a line count different from its lines. Got %d expected %d", lineCount, len(lines))
+	}
+	if lineCount < 7 {
+		t.Errorf("RichContent did not wrap across runs. Got %d lines", lineCount)
+	}
+	if _, err := r.Bytes(); err != nil {
+		t.Errorf("Bytes returned an error with rich content: %v", err)
+	}
+}
+
+func TestRichContentBaseline(t *testing.T) {
+	r := newFlowReport(t)
+	r.AddStyle("big", "Helvetica", "B", 24, AlignLeft)
+	r.AddBlock("tall", 12, 3)
+	r.AddPage()
+	r.Pdf.SetCompression(false)
+	if _, err := r.RichContent(1, 1, "tall", "body", `small <style name="big">Big</style> small`); err != nil {
+		t.Fatalf("RichContent returned an error: %v", err)
+	}
+	data, _ := r.Bytes()
+	texts := regexp.MustCompile(`BT [\d.]+ ([\d.]+) Td \(([^)]*)\) Tj ET`).FindAllSubmatch(data, -1)
+	if len(texts) != 3 {
+		t.Fatalf("RichContent did not draw 3 runs. Got %q", texts)
+	}
+	for _, text := range texts[1:] {
+		if string(text[1]) != string(texts[0][1]) {
+			t.Errorf("RichContent runs do not share a baseline. Got %s and %s", text[1], texts[0][1])
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package tps
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is a declarative description of a Report and its content, loaded from a
+// JSON or YAML document by LoadSpec(). Grid names like the orientation and page
+// size are the same strings the constants convert to, matched without case.
+// An example in YAML:
+//
+//	grid:
+//	  orientation: portrait
+//	  pageSize: letter
+//	  unit: pt
+//	  margin: 36
+//	  columns: 12
+//	  gutter: 12
+//	  lineHeight: 12
+//	styles:
+//	  body: {font: Helvetica, size: 10, align: left|top}
+//	blocks:
+//	  full: {width: 12, height: 1}
+//	content:
+//	  - {x: 1, y: 1, block: full, style: body, text: Hello}
+type Spec struct {
+	Grid     GridSpec             `json:"grid" yaml:"grid"`
+	FontPath string               `json:"fontPath" yaml:"fontPath"`
+	Fonts    []FontSpec           `json:"fonts" yaml:"fonts"`
+	Styles   map[string]StyleSpec `json:"styles" yaml:"styles"`
+	Blocks   map[string]Block     `json:"blocks" yaml:"blocks"`
+	Content  []Placement          `json:"content" yaml:"content"`
+}
+
+// GridSpec holds the arguments to Report.SetGrid() by name.
+type GridSpec struct {
+	Orientation string  `json:"orientation" yaml:"orientation"`
+	PageSize    string  `json:"pageSize" yaml:"pageSize"`
+	Unit        string  `json:"unit" yaml:"unit"`
+	Margin      float64 `json:"margin" yaml:"margin"`
+	Columns     int     `json:"columns" yaml:"columns"`
+	Gutter      float64 `json:"gutter" yaml:"gutter"`
+	LineHeight  float64 `json:"lineHeight" yaml:"lineHeight"`
+	// PageWidth and PageHeight give a custom page size in the unit
+	PageWidth  float64 `json:"pageWidth" yaml:"pageWidth"`
+	PageHeight float64 `json:"pageHeight" yaml:"pageHeight"`
+	// Margins replace Margin when set
+	Margins *Margins `json:"margins" yaml:"margins"`
+	// Baseline turns on the baseline grid with the offset when set
+	Baseline *float64 `json:"baseline" yaml:"baseline"`
+
+	position `json:"-" yaml:"-"`
+}
+
+// FontSpec holds the arguments to Report.AddFont().
+type FontSpec struct {
+	File     string `json:"file" yaml:"file"`
+	Encoding string `json:"encoding" yaml:"encoding"`
+}
+
+// StyleSpec holds the fields of a Style. Align is a list of alignment names
+// separated by "|", such as "left|top", and Border is a list of sides the same
+// way, such as "top|bottom" or "all". Colors are hex strings such as "#1f77b4"
+// or color names accepted by ParseColor().
+type StyleSpec struct {
+	Font        string  `json:"font" yaml:"font"`
+	FontStyle   string  `json:"fontStyle" yaml:"fontStyle"`
+	Size        float64 `json:"size" yaml:"size"`
+	Align       string  `json:"align" yaml:"align"`
+	Color       string  `json:"color" yaml:"color"`
+	Fill        string  `json:"fill" yaml:"fill"`
+	Border      string  `json:"border" yaml:"border"`
+	BorderWidth float64 `json:"borderWidth" yaml:"borderWidth"`
+	BorderColor string  `json:"borderColor" yaml:"borderColor"`
+	Padding     float64 `json:"padding" yaml:"padding"`
+
+	position `json:"-" yaml:"-"`
+}
+
+// Placement is one piece of content in a Spec. It is placed with
+// Report.Content() at X, Y, or with Report.Flow() at the cursor when Flow is
+// true. NewPage adds a page before placing the content.
+//
+// In a template, Range and Content repeat rows of content instead. See
+// ParseTemplate().
+type Placement struct {
+	X       int         `json:"x" yaml:"x"`
+	Y       int         `json:"y" yaml:"y"`
+	Block   string      `json:"block" yaml:"block"`
+	Style   string      `json:"style" yaml:"style"`
+	Text    string      `json:"text" yaml:"text"`
+	Flow    bool        `json:"flow" yaml:"flow"`
+	NewPage bool        `json:"newPage" yaml:"newPage"`
+	Range   string      `json:"range" yaml:"range"`
+	Content []Placement `json:"content" yaml:"content"`
+
+	position `json:"-" yaml:"-"`
+}
+
+// SpecError is returned by LoadSpec() for a problem at a Line and Column in the
+// spec document.
+type SpecError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("Spec line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying error so errors.Is and errors.As can inspect it.
+func (e *SpecError) Unwrap() error {
+	return e.Err
+}
+
+// position remembers where a value was found in the spec document.
+type position struct {
+	line, column int
+}
+
+func (p position) errorf(format string, args ...interface{}) error {
+	return &SpecError{Line: p.line, Column: p.column, Err: fmt.Errorf(format, args...)}
+}
+
+// UnmarshalYAML remembers where the grid was found in the spec document.
+func (g *GridSpec) UnmarshalYAML(node *yaml.Node) error {
+	type plain GridSpec
+	g.position = position{node.Line, node.Column}
+	return node.Decode((*plain)(g))
+}
+
+// UnmarshalYAML remembers where the style was found in the spec document.
+func (s *StyleSpec) UnmarshalYAML(node *yaml.Node) error {
+	type plain StyleSpec
+	s.position = position{node.Line, node.Column}
+	return node.Decode((*plain)(s))
+}
+
+// UnmarshalYAML remembers where the placement was found in the spec document.
+func (p *Placement) UnmarshalYAML(node *yaml.Node) error {
+	type plain Placement
+	p.position = position{node.Line, node.Column}
+	return node.Decode((*plain)(p))
+}
+
+// LoadSpec reads a JSON or YAML spec document and returns a Report with the
+// grid, fonts, styles and blocks set and all content placed.
+func LoadSpec(reader io.Reader) (*Report, error) {
+	spec, err := ReadSpec(reader)
+	if err != nil {
+		return nil, err
+	}
+	return spec.Report()
+}
+
+// ReadSpec reads a JSON or YAML spec document without creating the Report.
+func ReadSpec(reader io.Reader) (*Spec, error) {
+	spec := new(Spec)
+	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("Could not read spec: %v", err)
+	}
+	return spec, nil
+}
+
+// Report creates a Report from the spec and places all of its content.
+// Placements with a Range need ParseTemplate() instead.
+func (s *Spec) Report() (*Report, error) {
+	r, err := s.newReport()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.addFonts(r); err != nil {
+		return nil, err
+	}
+	if err = s.check(r, false); err != nil {
+		return nil, err
+	}
+	for _, p := range s.Content {
+		if _, err = p.place(r, 0, p.Text); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// newReport creates a Report with the grid, styles and blocks of the spec but
+// no fonts or content.
+func (s *Spec) newReport() (*Report, error) {
+	opts, err := s.options()
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReport(opts...)
+	if err != nil {
+		return nil, s.Grid.errorf("%v", err)
+	}
+	return r, nil
+}
+
+// addFonts compiles and adds the fonts of the spec to the report.
+func (s *Spec) addFonts(r *Report) error {
+	for _, font := range s.Fonts {
+		if err := r.AddFont(font.File, font.Encoding); err != nil {
+			return fmt.Errorf("Could not add font %s: %v", font.File, err)
+		}
+	}
+	return nil
+}
+
+// check makes sure every placement uses known block and style names, and that
+// ranges are only used in templates.
+func (s *Spec) check(r *Report, template bool) error {
+	for _, p := range s.Content {
+		if p.Range == "" && len(p.Content) == 0 {
+			if _, _, err := r.lookup(p.Block, p.Style); err != nil {
+				return p.errorf("%v", err)
+			}
+			continue
+		}
+		if !template {
+			return p.errorf("Range can only be used in a template")
+		}
+		if p.Range == "" {
+			return p.errorf("Content can only be nested in a range")
+		}
+		for _, child := range p.Content {
+			if child.Range != "" || len(child.Content) > 0 || child.Flow || child.NewPage {
+				return child.errorf("Content in a range cannot have a range, flow or new page")
+			}
+			if _, _, err := r.lookup(child.Block, child.Style); err != nil {
+				return child.errorf("%v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// place puts the placement in the report with text, at line + Y unless it
+// flows. Returns the # of lines taken up.
+func (p *Placement) place(r *Report, line int, text string) (lineCount int, err error) {
+	if p.NewPage {
+		r.AddPage()
+	}
+	if p.Flow {
+		lineCount, err = r.Flow(p.Block, p.Style, text)
+	} else {
+		lineCount, err = r.Content(p.X, line+p.Y, p.Block, p.Style, text)
+	}
+	if err != nil {
+		return lineCount, p.errorf("%v", err)
+	}
+	return lineCount, nil
+}
+
+// options converts the spec into the options for NewReport().
+func (s *Spec) options() ([]Option, error) {
+	g := s.Grid
+	o, ok := lookupName(orientation, g.Orientation)
+	if !ok {
+		return nil, g.errorf("Unknown orientation: %s", g.Orientation)
+	}
+	size, ok := PageSizeCustom, true
+	if g.PageSize != "" && (g.PageWidth != 0 || g.PageHeight != 0) {
+		return nil, g.errorf("Page size %s cannot be given with a page width and height", g.PageSize)
+	}
+	if g.PageSize != "" || g.PageWidth == 0 {
+		size, ok = lookupName(pageSize, g.PageSize)
+	}
+	if !ok {
+		return nil, g.errorf("Unknown page size: %s", g.PageSize)
+	}
+	u, ok := lookupName(unit, g.Unit)
+	if !ok {
+		return nil, g.errorf("Unknown unit: %s", g.Unit)
+	}
+	styles := make(map[string]Style)
+	for name, style := range s.Styles {
+		converted, err := style.style()
+		if err != nil {
+			return nil, style.errorf("Style %s: %v", name, err)
+		}
+		styles[name] = converted
+	}
+	opts := []Option{
+		WithGrid(o, size, u, g.Margin, g.Columns, g.Gutter, g.LineHeight),
+		WithStyles(styles),
+		WithBlocks(s.Blocks),
+	}
+	if size == PageSizeCustom {
+		opts = append(opts, WithPageDimensions(g.PageWidth, g.PageHeight))
+	}
+	if g.Margins != nil {
+		opts = append(opts, WithMargins(*g.Margins))
+	}
+	if g.Baseline != nil {
+		opts = append(opts, WithBaselineGrid(*g.Baseline))
+	}
+	if s.FontPath != "" {
+		opts = append(opts, WithFontPath(s.FontPath))
+	}
+	return opts, nil
+}
+
+// style converts the spec into a Style.
+func (s *StyleSpec) style() (Style, error) {
+	style := Style{
+		FontFamily:  s.Font,
+		FontStyle:   s.FontStyle,
+		FontSize:    s.Size,
+		BorderWidth: s.BorderWidth,
+		Padding:     s.Padding,
+	}
+	var err error
+	if style.Alignment, err = parseNames(alignmentNames, "alignment", s.Align); err != nil {
+		return style, err
+	}
+	if s.Border == "all" {
+		style.Border = BorderAll
+	} else if style.Border, err = parseNames(borderNames, "border", s.Border); err != nil {
+		return style, err
+	}
+	if s.Color != "" {
+		if style.TextColor, err = ParseColor(s.Color); err != nil {
+			return style, err
+		}
+	}
+	if s.Fill != "" {
+		if style.FillColor, err = ParseColor(s.Fill); err != nil {
+			return style, err
+		}
+		style.Fill = true
+	}
+	if s.BorderColor != "" {
+		if style.BorderColor, err = ParseColor(s.BorderColor); err != nil {
+			return style, err
+		}
+	}
+	return style, nil
+}
+
+// lookupName finds the constant for name in one of the conversion maps.
+func lookupName(names map[int]string, name string) (int, bool) {
+	for constant, value := range names {
+		if strings.EqualFold(value, name) {
+			return constant, true
+		}
+	}
+	return 0, false
+}
+
+// alignmentNames are the names used for the Align* constants in specs.
+var alignmentNames = map[int]string{
+	AlignLeft:   "left",
+	AlignCenter: "center",
+	AlignRight:  "right",
+	AlignTop:    "top",
+	AlignMiddle: "middle",
+	AlignBottom: "bottom",
+}
+
+// borderNames are the names used for the Border* constants in specs.
+var borderNames = map[int]string{
+	BorderLeft:   "left",
+	BorderTop:    "top",
+	BorderRight:  "right",
+	BorderBottom: "bottom",
+}
+
+// parseNames converts names separated by "|" into the constants OR'd together,
+// such as alignment names into the Align* constants.
+func parseNames(names map[int]string, kind, value string) (int, error) {
+	constants := 0
+	if strings.TrimSpace(value) == "" {
+		return constants, nil
+	}
+	for _, name := range strings.Split(value, "|") {
+		constant, ok := lookupName(names, strings.TrimSpace(name))
+		if !ok {
+			return 0, fmt.Errorf("Unknown %s: %s", kind, name)
+		}
+		constants |= constant
+	}
+	return constants, nil
+}
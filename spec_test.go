@@ -0,0 +1,92 @@
+package tps
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testSpecYAML = `
+grid:
+  orientation: portrait
+  pageSize: letter
+  unit: pt
+  margin: 36
+  columns: 12
+  gutter: 12
+  lineHeight: 12
+styles:
+  body: {font: Helvetica, size: 10, align: left|top}
+  total: {font: Helvetica, size: 10, color: "#1f77b4", fill: silver, border: top|bottom, padding: 2}
+blocks:
+  full: {width: 12, height: 1}
+content:
+  - {x: 1, y: 1, block: full, style: body, text: Hello}
+  - {flow: true, block: full, style: body, text: World}
+`
+
+const testSpecJSON = `{
+  "grid": {"orientation": "Landscape", "pageSize": "A4", "unit": "mm",
+    "margin": 10, "columns": 6, "gutter": 5, "lineHeight": 6},
+  "styles": {"body": {"font": "Helvetica", "size": 10}},
+  "blocks": {"half": {"width": 3, "height": 1}},
+  "content": [
+    {"x": 1, "y": 1, "block": "half", "style": "body", "text": "Hello"},
+    {"x": 4, "y": 1, "block": "half", "style": "missing", "text": "World"}
+  ]
+}`
+
+func TestLoadSpec(t *testing.T) {
+	r, err := LoadSpec(strings.NewReader(testSpecYAML))
+	if err != nil {
+		t.Fatalf("LoadSpec returned an error: %v", err)
+	}
+	if r.Grid.ColumnWidth != 34.0 {
+		t.Errorf("LoadSpec did not set the grid. Got column width %.1f expected %.1f", r.Grid.ColumnWidth, 34.0)
+	}
+	e := Style{FontFamily: "Helvetica", FontSize: 10, Alignment: AlignLeft | AlignTop}
+	if s := r.Styles["body"]; s != e {
+		t.Errorf("LoadSpec did not add style. Got %v expected %v", s, e)
+	}
+	e = Style{
+		FontFamily: "Helvetica",
+		FontSize:   10,
+		TextColor:  Color{31, 119, 180},
+		FillColor:  Color{192, 192, 192},
+		Fill:       true,
+		Border:     BorderTop | BorderBottom,
+		Padding:    2,
+	}
+	if s := r.Styles["total"]; s != e {
+		t.Errorf("LoadSpec did not add style with colors. Got %v expected %v", s, e)
+	}
+	if r.Cursor.Y != 2 {
+		t.Errorf("LoadSpec did not flow content. Got cursor %d expected %d", r.Cursor.Y, 2)
+	}
+}
+
+func TestLoadSpecErrors(t *testing.T) {
+	var specErr *SpecError
+	_, err := LoadSpec(strings.NewReader(testSpecJSON))
+	if !errors.As(err, &specErr) {
+		t.Fatalf("LoadSpec did not return a SpecError. Got %v", err)
+	}
+	if specErr.Line != 8 || specErr.Column != 5 {
+		t.Errorf("LoadSpec returned wrong position. Got %d:%d expected 8:5", specErr.Line, specErr.Column)
+	}
+
+	tests := map[string]string{
+		"orientation": strings.Replace(testSpecYAML, "portrait", "sideways", 1),
+		"alignment":   strings.Replace(testSpecYAML, "left|top", "left|up", 1),
+		"field":       testSpecYAML + "colour: red\n",
+		"columns":     strings.Replace(testSpecYAML, "columns: 12", "columns: 0", 1),
+		"color":       strings.Replace(testSpecYAML, "#1f77b4", "#1f77", 1),
+		"border":      strings.Replace(testSpecYAML, "top|bottom", "top|middle", 1),
+		"page size":   strings.Replace(testSpecYAML, "pageSize: letter", "pageSize: letter\n  pageWidth: 200", 1),
+	}
+	for name, spec := range tests {
+		if _, err := LoadSpec(strings.NewReader(spec)); err == nil {
+			t.Errorf("LoadSpec did not return an error for bad %s", name)
+		}
+	}
+}
@@ -1,12 +1,79 @@
 package tps
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Style is a specification of the content visuals. All content placement
 // requires a style name, and cannot be provided dynamically.
+//
+// TextColor defaults to black and when Fill is true FillColor fills the
+// background of the placement. Border is the sides to draw, such as
+// BorderTop | BorderBottom, with BorderWidth in the Grid.Unit system. Padding
+// is the space between the text and the left and right sides of the block, in
+// the Grid.Unit system, with 0 keeping the Pdf's default cell margin.
 type Style struct {
-	FontFamily string
-	FontStyle  string
-	FontSize   float64
-	Alignment  int
+	FontFamily  string
+	FontStyle   string
+	FontSize    float64
+	Alignment   int
+	TextColor   Color
+	FillColor   Color
+	Fill        bool
+	Border      int
+	BorderWidth float64
+	BorderColor Color
+	Padding     float64
+}
+
+// Color is an RGB color with each component ranging from 0 to 255.
+type Color struct {
+	R, G, B int
+}
+
+// colorNames are the named colors ParseColor accepts.
+var colorNames = map[string]Color{
+	"black":   {0, 0, 0},
+	"white":   {255, 255, 255},
+	"gray":    {128, 128, 128},
+	"grey":    {128, 128, 128},
+	"silver":  {192, 192, 192},
+	"red":     {255, 0, 0},
+	"maroon":  {128, 0, 0},
+	"orange":  {255, 165, 0},
+	"yellow":  {255, 255, 0},
+	"olive":   {128, 128, 0},
+	"lime":    {0, 255, 0},
+	"green":   {0, 128, 0},
+	"aqua":    {0, 255, 255},
+	"teal":    {0, 128, 128},
+	"blue":    {0, 0, 255},
+	"navy":    {0, 0, 128},
+	"fuchsia": {255, 0, 255},
+	"purple":  {128, 0, 128},
+}
+
+// ParseColor converts a hex string such as "#1f77b4" or "#fff", or a basic
+// color name such as "navy", into a Color.
+func ParseColor(value string) (Color, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if color, ok := colorNames[value]; ok {
+		return color, nil
+	}
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if !strings.HasPrefix(value, "#") || len(hex) != 6 {
+		return Color{}, fmt.Errorf("Invalid color: %s", value)
+	}
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("Invalid color: %s", value)
+	}
+	return Color{int(rgb >> 16 & 0xff), int(rgb >> 8 & 0xff), int(rgb & 0xff)}, nil
 }
 
 func (s *Style) convertAlignment() string {
@@ -18,3 +85,94 @@ func (s *Style) convertAlignment() string {
 	}
 	return val
 }
+
+func (s *Style) convertBorder() string {
+	val := ""
+	for borderConst, stringVal := range border {
+		if ok := s.Border & borderConst; ok > 0 {
+			val += stringVal
+		}
+	}
+	return val
+}
+
+// StyleOption overrides part of a Style. They are used to derive a style with
+// Report.AddStyleFrom(), or when placing content for a one-off change such as:
+//
+//	r.Content(1, 10, "full", "body", "Total", StyleBold(), StyleFontSize(12))
+type StyleOption func(*Style)
+
+// StyleFont overrides the font family.
+func StyleFont(fontFamily string) StyleOption {
+	return func(s *Style) {
+		s.FontFamily = fontFamily
+	}
+}
+
+// StyleBold adds bold to the font style.
+func StyleBold() StyleOption {
+	return fontStyleOption("B")
+}
+
+// StyleItalic adds italic to the font style.
+func StyleItalic() StyleOption {
+	return fontStyleOption("I")
+}
+
+// StyleUnderline adds underline to the font style.
+func StyleUnderline() StyleOption {
+	return fontStyleOption("U")
+}
+
+func fontStyleOption(fontStyle string) StyleOption {
+	return func(s *Style) {
+		if !strings.Contains(strings.ToUpper(s.FontStyle), fontStyle) {
+			s.FontStyle += fontStyle
+		}
+	}
+}
+
+// StyleFontSize overrides the font size.
+func StyleFontSize(fontSize float64) StyleOption {
+	return func(s *Style) {
+		s.FontSize = fontSize
+	}
+}
+
+// StyleAlignment overrides the alignment.
+func StyleAlignment(alignment int) StyleOption {
+	return func(s *Style) {
+		s.Alignment = alignment
+	}
+}
+
+// StyleTextColor overrides the text color.
+func StyleTextColor(color Color) StyleOption {
+	return func(s *Style) {
+		s.TextColor = color
+	}
+}
+
+// StyleFillColor overrides the fill color.
+func StyleFillColor(color Color) StyleOption {
+	return func(s *Style) {
+		s.FillColor = color
+		s.Fill = true
+	}
+}
+
+// StyleBorder overrides the border sides, width and color.
+func StyleBorder(border int, width float64, color Color) StyleOption {
+	return func(s *Style) {
+		s.Border = border
+		s.BorderWidth = width
+		s.BorderColor = color
+	}
+}
+
+// StylePadding overrides the padding.
+func StylePadding(padding float64) StyleOption {
+	return func(s *Style) {
+		s.Padding = padding
+	}
+}
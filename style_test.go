@@ -36,3 +36,51 @@ func TestConvertAlignment(t *testing.T) {
 		}
 	}
 }
+
+func TestConvertBorder(t *testing.T) {
+	s := Style{Border: BorderAll}
+	value := s.convertBorder()
+	if len(value) != 4 {
+		t.Errorf("Style.convertBorder failed. Expected all four sides got \"%s\"", value)
+	}
+	s.Border = 0
+	if value = s.convertBorder(); value != "" {
+		t.Errorf("Style.convertBorder failed. Expected no sides got \"%s\"", value)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := map[string]Color{
+		"#1f77b4": {31, 119, 180},
+		"#FFF":    {255, 255, 255},
+		"navy":    {0, 0, 128},
+		" Grey ":  {128, 128, 128},
+	}
+	for value, expected := range tests {
+		color, err := ParseColor(value)
+		if err != nil || color != expected {
+			t.Errorf("ParseColor failed for %q. Got %v %v expected %v", value, color, err, expected)
+		}
+	}
+	for _, value := range []string{"", "#12", "1f77b4", "#gggggg", "chartreuse"} {
+		if _, err := ParseColor(value); err == nil {
+			t.Errorf("ParseColor did not return an error for %q", value)
+		}
+	}
+}
+
+func TestFillColor(t *testing.T) {
+	var s Style
+	if s.Fill {
+		t.Error("Style fills by default.")
+	}
+	StyleFillColor(Color{1, 2, 3})(&s)
+	copied := s
+	copied.FillColor.R = 9
+	if !s.Fill || s.FillColor != (Color{1, 2, 3}) {
+		t.Errorf("StyleFillColor did not set an independent fill color. Got %v", s)
+	}
+	if copied == s {
+		t.Error("Style copies compare equal with different fill colors.")
+	}
+}
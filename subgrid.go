@@ -0,0 +1,74 @@
+package tps
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sub returns a child Grid covering the block at the x, y grid coordinates
+// with its own # of columns and gutter width. Its GetPoint() and GetCell() are
+// relative to the block, so a component laid out in the child's coordinates
+// can be placed anywhere on the page. The line height is the parent's, so
+// child lines stay on the parent's lines. For example, a 4 column sidebar in
+// the last 3 columns of a 12 column page:
+//
+//   sidebar, err := r.Grid.Sub(10, 1, Block{Width: 3, Height: 60}, 4, 6)
+//
+// The child is placed on the parent's current page, so with mirrored margins
+// a new child is needed for a page with the other parity.
+func (g *Grid) Sub(x, y int, block Block, columns int, gutter float64) (Grid, error) {
+	if x < 1 || y < 1 || block.Width < 1 || block.Height < 1 {
+		return Grid{}, fmt.Errorf("Sub grid position and size must be positive: %d, %d %+v", x, y, block)
+	}
+	if x-1+block.Width > g.ColumnCount || y-1+block.Height > g.LineCount() {
+		return Grid{}, fmt.Errorf("Sub grid does not fit in the grid: %d, %d %+v", x, y, block)
+	}
+	if columns < 1 || gutter <= 0 {
+		return Grid{}, errors.New("Sub grid column count and gutter width must be positive")
+	}
+
+	point := g.GetPoint(x, y)
+	cell := g.GetCell(block)
+	child := *g
+	child.ColumnCount = columns
+	child.GutterWidth = gutter
+	child.Margin = 0
+	child.Margins = Margins{
+		Top:    point.Y,
+		Right:  g.PageWidth - point.X - cell.Width,
+		Bottom: g.PageHeight - point.Y - cell.Height,
+		Left:   point.X,
+	}
+	if err := child.CalculateColumns(); err != nil {
+		return Grid{}, err
+	}
+	if child.ColumnWidth <= 0 {
+		return Grid{}, errors.New("Sub grid gutters are wider than the block")
+	}
+	return child, nil
+}
+
+// Within places content on a sub grid from Grid.Sub(). The Report grid is the
+// sub grid while layout runs, with the cursor at its top left, and both are
+// put back afterwards. Pages added by layout, such as when Flow() overflows
+// the sub grid, are still set up with the page grid for the margins, header
+// and footer, and AddPageWith() returns an error. For example:
+//
+//   r.Within(sidebar, func() error {
+//   	_, err := r.Content(1, 1, "quarter", "body", "Contents")
+//   	return err
+//   })
+func (r *Report) Within(grid Grid, layout func() error) error {
+	parent, cursor, pageGrid := r.Grid, r.Cursor, r.pageGrid
+	if pageGrid == nil {
+		r.pageGrid = &parent
+	}
+	defer func() {
+		page := r.Grid.Page
+		r.Grid, r.Cursor, r.pageGrid = parent, cursor, pageGrid
+		r.Grid.Page = page
+	}()
+	r.Grid = grid
+	r.Cursor = Cursor{X: 1, Y: 1}
+	return layout()
+}
@@ -0,0 +1,108 @@
+package tps
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSub(t *testing.T) {
+	g := newGrid()
+	sub, err := g.Sub(10, 5, Block{Width: 3, Height: 10}, 4, 6)
+	if err != nil {
+		t.Fatalf("Sub returned an error: %v", err)
+	}
+	// the block starts at 36 + 9 * (34 + 12) and is 3 * 34 + 2 * 12 wide
+	if point := sub.GetPoint(1, 1); point.X != 450 || point.Y != 84 {
+		t.Errorf("Sub did not start at the block. Got %+v", point)
+	}
+	if sub.ColumnWidth != 27 || sub.LineCount() != 10 {
+		t.Errorf("Sub did not calculate its columns and lines. Got %g and %d", sub.ColumnWidth, sub.LineCount())
+	}
+	if point := sub.GetPoint(4, 2); point.X != 549 || point.Y != 96 {
+		t.Errorf("Sub did not place relative to the block. Got %+v", point)
+	}
+	if cell := sub.GetCell(Block{Width: 4, Height: 1}); cell.Width != 126 {
+		t.Errorf("Sub cells do not fill the block. Got %g expected %g", cell.Width, 126.0)
+	}
+
+	nested, err := sub.Sub(3, 1, Block{Width: 2, Height: 1}, 2, 1)
+	if err != nil || nested.GetPoint(1, 1).X != 516 {
+		t.Errorf("Sub did not nest. Got %+v error %v", nested.GetPoint(1, 1), err)
+	}
+
+	if _, err = g.Sub(11, 1, Block{Width: 3, Height: 1}, 4, 6); err == nil {
+		t.Error("Sub did not reject a block outside the grid.")
+	}
+	if _, err = g.Sub(1, 1, Block{Width: 1, Height: 1}, 10, 6); err == nil {
+		t.Error("Sub did not reject gutters wider than the block.")
+	}
+}
+
+func TestWithin(t *testing.T) {
+	r := newFlowReport(t)
+	r.AddBlock("quarter", 1, 1)
+	sub, _ := r.Grid.Sub(7, 3, Block{Width: 6, Height: 10}, 4, 6)
+	r.SetCursor(2, 5)
+	err := r.Within(sub, func() error {
+		if point := r.Grid.GetPoint(1, 1); point.X != 312 || point.Y != 60 {
+			t.Errorf("Within did not use the sub grid. Got %+v", point)
+		}
+		_, err := r.Flow("quarter", "body", "Contents")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Within returned an error: %v", err)
+	}
+	if r.Grid.ColumnCount != 12 || r.Cursor != (Cursor{2, 5}) {
+		t.Errorf("Within did not put the grid and cursor back. Got %d columns and %+v", r.Grid.ColumnCount, r.Cursor)
+	}
+}
+
+func TestWithinNewPage(t *testing.T) {
+	r := newFlowReport(t)
+	r.AddBlock("quarter", 1, 1)
+	r.Pdf.SetCompression(false)
+	if err := r.SetHeader(PageContent{X: 1, Y: 0, Block: "full", Style: "body", Text: "Header"}); err != nil {
+		t.Fatal(err)
+	}
+	r.AddPage()
+	sub, _ := r.Grid.Sub(7, 3, Block{Width: 6, Height: 10}, 4, 6)
+	err := r.Within(sub, func() error {
+		_, err := r.Flow("quarter", "body", strings.Repeat("line\n", 15))
+		if point := r.Grid.GetPoint(1, 1); point.X != 312 || point.Y != 60 {
+			t.Errorf("Within did not keep the sub grid after a new page. Got %+v", point)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Within returned an error: %v", err)
+	}
+	if r.Pdf.PageNo() != 2 || r.Grid.Page != 2 {
+		t.Fatalf("Flow did not add a page within the sub grid. Got page %d", r.Pdf.PageNo())
+	}
+	if left, top, right, _ := r.Pdf.GetMargins(); left != 36 || top != 36 || right != 36 {
+		t.Errorf("AddPage set the Pdf margins of the sub grid. Got %g %g %g", left, top, right)
+	}
+	if err = r.Within(sub, func() error { return r.AddPageWith(OrientationLandscape, PageSizeLetter) }); err == nil {
+		t.Error("AddPageWith did not return an error within a sub grid")
+	}
+
+	data, err := r.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	headers := regexp.MustCompile(`BT ([\d.]+) ([\d.]+) Td \(Header\)Tj ET`).FindAllSubmatch(data, -1)
+	if len(headers) != 2 {
+		t.Fatalf("AddPage did not place the header on both pages. Got %d", len(headers))
+	}
+	for _, header := range headers {
+		if x, _ := strconv.ParseFloat(string(header[1]), 64); x > 40 {
+			t.Errorf("AddPage placed the header on the sub grid. Got x %g", x)
+		}
+		if string(header[2]) != string(headers[0][2]) {
+			t.Errorf("AddPage moved the header on the second page. Got y %s and %s", headers[0][2], header[2])
+		}
+	}
+}
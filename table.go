@@ -0,0 +1,195 @@
+package tps
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TableColumn is the specification of one column in a Table. Width is the # of
+// grid columns it spans, the same as Block.Width. Style and HeaderStyle are
+// the style names used for body and header cells. A non-zero Alignment
+// overrides the alignment of both styles for this column.
+type TableColumn struct {
+	Width       int
+	Style       string
+	HeaderStyle string
+	Alignment   int
+}
+
+// Table is placed with Report.FlowTable(). Header rows are placed first and
+// repeated at the top of every page the table continues on. Height is the #
+// of grid lines each line of cell text takes up, the same as Block.Height,
+// and defaults to 1. When Stripe is true every other body row is filled with
+// StripeColor.
+type Table struct {
+	Columns     []TableColumn
+	Header      [][]string
+	Rows        [][]string
+	Height      int
+	Stripe      bool
+	StripeColor Color
+}
+
+// tableRow is a table row with its cells resolved and measured.
+type tableRow struct {
+	cells     []tableCell
+	lineCount int
+}
+
+type tableCell struct {
+	block   Block
+	style   Style
+	content string
+}
+
+// FlowTable places the table at the cursor like Flow(), with the first column
+// at the cursor column. Rows are never split across pages; a row that would
+// cross the bottom margin starts a new page with the header rows repeated.
+// Returns the total # of lines taken up across all pages.
+func (r *Report) FlowTable(t *Table) (lineCount int, err error) {
+	if err = r.checkTable(t); err != nil {
+		return 0, err
+	}
+	header, headerLines, err := r.prepareRows(t, t.Header, true)
+	if err != nil {
+		return 0, err
+	}
+	rows, _, err := r.prepareRows(t, t.Rows, false)
+	if err != nil {
+		return 0, err
+	}
+
+	pageLines := r.Grid.LineCount()
+	for i, row := range rows {
+		if headerLines+row.lineCount > pageLines {
+			return 0, fmt.Errorf("Table row %d does not fit on a page", i+1)
+		}
+	}
+	r.startPage()
+
+	// keep the header together with the first row
+	first := headerLines
+	if len(rows) > 0 {
+		first += rows[0].lineCount
+	}
+	if r.Cursor.Y+first-1 > pageLines {
+		r.AddPage()
+	}
+	lineCount += r.placeRows(t, header, false)
+
+	for i, row := range rows {
+		if r.Cursor.Y+row.lineCount-1 > pageLines {
+			r.AddPage()
+			lineCount += r.placeRows(t, header, false)
+		}
+		lineCount += r.placeRows(t, []tableRow{row}, t.Stripe && i%2 == 1)
+	}
+	return lineCount, nil
+}
+
+// checkTable makes sure the table columns fit in the grid from the cursor.
+func (r *Report) checkTable(t *Table) error {
+	if len(t.Columns) == 0 {
+		return errors.New("Table has no columns")
+	}
+	width := 0
+	for i, column := range t.Columns {
+		if column.Width <= 0 {
+			return fmt.Errorf("Table column %d width must be positive", i+1)
+		}
+		width += column.Width
+	}
+	if r.Cursor.X-1+width > r.Grid.ColumnCount {
+		return fmt.Errorf(
+			"Table is %d columns wide and does not fit in the grid from column %d",
+			width,
+			r.Cursor.X,
+		)
+	}
+	return nil
+}
+
+// prepareRows resolves the styles of every cell and measures each row. Returns
+// the rows and the total # of lines they take up.
+func (r *Report) prepareRows(t *Table, cells [][]string, header bool) ([]tableRow, int, error) {
+	height := t.Height
+	if height <= 0 {
+		height = 1
+	}
+	rows := make([]tableRow, len(cells))
+	total := 0
+	for i, contents := range cells {
+		if len(contents) > len(t.Columns) {
+			return nil, 0, fmt.Errorf(
+				"Table row %d has %d cells but only %d columns",
+				i+1,
+				len(contents),
+				len(t.Columns),
+			)
+		}
+		row := tableRow{cells: make([]tableCell, len(t.Columns))}
+		wrapped := 1
+		for j, column := range t.Columns {
+			styleName := column.Style
+			if header && column.HeaderStyle != "" {
+				styleName = column.HeaderStyle
+			}
+			style, err := r.Style(styleName)
+			if err != nil {
+				return nil, 0, err
+			}
+			if column.Alignment != 0 {
+				style.Alignment = column.Alignment
+			}
+			cell := tableCell{
+				block: Block{Width: column.Width, Height: height},
+				style: style,
+			}
+			if j < len(contents) {
+				cell.content = contents[j]
+			}
+			if err := r.checkGlyphs(style, cell.content); err != nil {
+				return nil, 0, err
+			}
+			lines, err := r.wrap(cell.block, style, cell.content)
+			if err != nil {
+				return nil, 0, err
+			}
+			if len(lines) > wrapped {
+				wrapped = len(lines)
+			}
+			row.cells[j] = cell
+		}
+		row.lineCount = wrapped * height
+		total += row.lineCount
+		rows[i] = row
+	}
+	return rows, total, nil
+}
+
+// placeRows places the rows at the cursor, moving it down past them, and
+// returns the # of lines taken up.
+func (r *Report) placeRows(t *Table, rows []tableRow, stripe bool) (lineCount int) {
+	for _, row := range rows {
+		if stripe {
+			width := 0
+			for _, cell := range row.cells {
+				width += cell.block.Width
+			}
+			point := r.Grid.GetPoint(r.Cursor.X, r.Cursor.Y)
+			cell := r.Grid.GetCell(Block{Width: width, Height: row.lineCount})
+			restore := r.saveDrawing()
+			r.Pdf.SetFillColor(t.StripeColor.R, t.StripeColor.G, t.StripeColor.B)
+			r.Pdf.Rect(point.X, point.Y, cell.Width, cell.Height, "F")
+			restore()
+		}
+		x := r.Cursor.X
+		for _, cell := range row.cells {
+			r.place(x, r.Cursor.Y, cell.block, cell.style, cell.content, "")
+			x += cell.block.Width
+		}
+		r.Cursor.Y += row.lineCount
+		lineCount += row.lineCount
+	}
+	return lineCount
+}
@@ -0,0 +1,72 @@
+package tps
+
+import (
+	"fmt"
+	"testing"
+)
+
+func newTestTable() *Table {
+	return &Table{
+		Columns: []TableColumn{
+			{Width: 8, Style: "body"},
+			{Width: 4, Style: "body", Alignment: AlignRight},
+		},
+		Header: [][]string{{"Item", "Amount"}},
+	}
+}
+
+func TestFlowTable(t *testing.T) {
+	r := newFlowReport(t)
+	table := newTestTable()
+	table.Rows = [][]string{{"Apples", "1.00"}, {"Pears", "2.00"}, {"Plums"}}
+	lineCount, err := r.FlowTable(table)
+	if err != nil {
+		t.Fatalf("FlowTable returned an error: %v", err)
+	}
+	if lineCount != 4 {
+		t.Errorf("FlowTable returned wrong line count. Got %d expected %d", lineCount, 4)
+	}
+	if r.Cursor.Y != 5 {
+		t.Errorf("FlowTable did not advance cursor. Got %d expected %d", r.Cursor.Y, 5)
+	}
+}
+
+func TestFlowTableRepeatsHeader(t *testing.T) {
+	r := newFlowReport(t)
+	r.SetCursor(1, 50)
+	table := newTestTable()
+	table.Stripe = true
+	table.StripeColor = Color{240, 240, 240}
+	for i := 0; i < 20; i++ {
+		table.Rows = append(table.Rows, []string{fmt.Sprintf("Item %d", i), "1.00"})
+	}
+	lineCount, err := r.FlowTable(table)
+	if err != nil {
+		t.Fatalf("FlowTable returned an error: %v", err)
+	}
+	// header and 10 rows on the first page, header and 10 rows on the second
+	if lineCount != 22 {
+		t.Errorf("FlowTable returned wrong line count. Got %d expected %d", lineCount, 22)
+	}
+	if r.Pdf.PageNo() != 2 || r.Cursor.Y != 12 {
+		t.Errorf("FlowTable did not break the page. Got page %d cursor %d", r.Pdf.PageNo(), r.Cursor.Y)
+	}
+	if red, green, blue := r.Pdf.GetFillColor(); red != 0 || green != 0 || blue != 0 {
+		t.Errorf("FlowTable did not restore the fill color. Got %d %d %d", red, green, blue)
+	}
+}
+
+func TestFlowTableErrors(t *testing.T) {
+	r := newFlowReport(t)
+	tests := map[string]*Table{
+		"no columns": {},
+		"too wide":   {Columns: []TableColumn{{Width: 13, Style: "body"}}},
+		"bad style":  {Columns: []TableColumn{{Width: 1, Style: "missing"}}, Rows: [][]string{{"a"}}},
+		"extra cell": {Columns: []TableColumn{{Width: 1, Style: "body"}}, Rows: [][]string{{"a", "b"}}},
+	}
+	for name, table := range tests {
+		if _, err := r.FlowTable(table); err == nil {
+			t.Errorf("FlowTable did not return an error for %s", name)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package tps
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// Template is a Spec whose placement text is Go text/template templates,
+// executed against data to create each Report. A placement with a Range
+// repeats its nested Content for each element of the Range pipeline, such as
+// one row per invoice item, with the element as the template data:
+//
+//	content:
+//	  - {x: 1, y: 1, block: full, style: header, text: "Invoice {{.Number}}"}
+//	  - range: .Items
+//	    y: 3
+//	    content:
+//	      - {x: 1, block: name, style: body, text: "{{.Name}}"}
+//	      - {x: 9, block: amount, style: total, text: "{{printf \"%.2f\" .Amount}}"}
+//
+// Rows start at the Y of the range, or at the cursor when it flows, and the Y
+// of nested content is the offset from the line of the row. Each row takes up
+// the most lines of its content, and with flow a row that would cross the
+// bottom margin starts a new page.
+//
+// A Template does not change once parsed, so Execute() can be called from many
+// goroutines at once. The fonts of the spec are compiled once by NewTemplate()
+// and added to each Report from memory.
+type Template struct {
+	spec   *Spec
+	texts  map[*Placement]*template.Template
+	ranges map[*Placement]*template.Template
+	fonts  []templateFont
+}
+
+// templateFont is a compiled font added to every Report of a Template.
+type templateFont struct {
+	family     string
+	definition []byte
+	data       []byte
+}
+
+// ParseTemplate reads a JSON or YAML spec document and parses the templates in
+// it. Errors are a *SpecError with the position of the placement.
+func ParseTemplate(reader io.Reader) (*Template, error) {
+	spec, err := ReadSpec(reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewTemplate(spec)
+}
+
+// NewTemplate parses the templates in a spec and compiles its fonts. The spec
+// must not be changed afterwards.
+func NewTemplate(spec *Spec) (*Template, error) {
+	t := &Template{
+		spec:   spec,
+		texts:  make(map[*Placement]*template.Template),
+		ranges: make(map[*Placement]*template.Template),
+	}
+	if err := t.compileFonts(); err != nil {
+		return nil, err
+	}
+	for i := range spec.Content {
+		p := &spec.Content[i]
+		if err := t.parse(p); err != nil {
+			return nil, err
+		}
+		for j := range p.Content {
+			if err := t.parse(&p.Content[j]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return t, nil
+}
+
+// compileFonts compiles the fonts of the spec and keeps them in memory.
+func (t *Template) compileFonts() error {
+	if len(t.spec.Fonts) == 0 {
+		return nil
+	}
+	r, err := t.spec.newReport()
+	if err != nil {
+		return err
+	}
+	for _, font := range t.spec.Fonts {
+		family := strings.TrimSuffix(path.Base(font.File), path.Ext(font.File))
+		definition, data, err := r.loadFont(font.File, font.Encoding)
+		if err != nil {
+			return fmt.Errorf("Could not add font %s: %v", font.File, err)
+		}
+		t.fonts = append(t.fonts, templateFont{family, definition, data})
+	}
+	return nil
+}
+
+func (t *Template) parse(p *Placement) error {
+	text, err := template.New("text").Option("missingkey=error").Parse(p.Text)
+	if err != nil {
+		return p.errorf("Could not parse text template: %v", err)
+	}
+	t.texts[p] = text
+	if p.Range == "" {
+		return nil
+	}
+	pipeline := strings.TrimSpace(p.Range)
+	pipeline = strings.TrimSuffix(strings.TrimPrefix(pipeline, "{{"), "}}")
+	// the template engine iterates so the order is that of the range action,
+	// and collect is replaced on each execution to catch the elements
+	collect := template.FuncMap{"collect": func(interface{}) string { return "" }}
+	rangeTemplate, err := template.New("range").
+		Option("missingkey=error").
+		Funcs(collect).
+		Parse("{{range (" + pipeline + ")}}{{collect .}}{{end}}")
+	if err != nil {
+		return p.errorf("Could not parse range: %v", err)
+	}
+	t.ranges[p] = rangeTemplate
+	return nil
+}
+
+// Execute creates a Report from the spec with the templates executed against
+// data. Errors are a *SpecError with the position of the placement.
+func (t *Template) Execute(data interface{}) (*Report, error) {
+	r, err := t.spec.newReport()
+	if err != nil {
+		return nil, err
+	}
+	for _, font := range t.fonts {
+		r.Pdf.AddFontFromBytes(font.family, "", font.definition, font.data)
+	}
+	if err = r.Pdf.Error(); err != nil {
+		return nil, fmt.Errorf("Could not add fonts: %v", err)
+	}
+	if err = t.spec.check(r, true); err != nil {
+		return nil, err
+	}
+	for i := range t.spec.Content {
+		p := &t.spec.Content[i]
+		if p.Range != "" {
+			err = t.placeRange(r, p, data)
+		} else {
+			var text string
+			if text, err = t.text(p, data); err == nil {
+				_, err = p.place(r, 0, text)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (t *Template) text(p *Placement, data interface{}) (string, error) {
+	var b strings.Builder
+	if err := t.texts[p].Execute(&b, data); err != nil {
+		return "", p.errorf("Could not execute text template: %v", err)
+	}
+	return b.String(), nil
+}
+
+// items evaluates the range of a placement into the elements to repeat for.
+// The range action of text/template does the iterating, so maps are ordered
+// by key the same way, with numbers compared numerically.
+func (t *Template) items(p *Placement, data interface{}) ([]interface{}, error) {
+	var items []interface{}
+	rangeTemplate, err := t.ranges[p].Clone()
+	if err != nil {
+		return nil, p.errorf("Could not execute range: %v", err)
+	}
+	rangeTemplate.Funcs(template.FuncMap{"collect": func(item interface{}) string {
+		items = append(items, item)
+		return ""
+	}})
+	if err = rangeTemplate.Execute(ioutil.Discard, data); err != nil {
+		return nil, p.errorf("Could not execute range: %v", err)
+	}
+
+	return items, nil
+}
+
+func (t *Template) placeRange(r *Report, p *Placement, data interface{}) error {
+	items, err := t.items(p, data)
+	if err != nil {
+		return err
+	}
+	if p.NewPage {
+		r.AddPage()
+	}
+	r.startPage()
+
+	line := p.Y
+	texts := make([]string, len(p.Content))
+	for _, item := range items {
+		rowLines := 1
+		for i := range p.Content {
+			child := &p.Content[i]
+			if texts[i], err = t.text(child, item); err != nil {
+				return err
+			}
+			m, err := r.Measure(child.Block, child.Style, texts[i])
+			if err != nil {
+				return child.errorf("%v", err)
+			}
+			if child.Y+m.LineCount > rowLines {
+				rowLines = child.Y + m.LineCount
+			}
+		}
+		if p.Flow {
+			if r.Cursor.Y+rowLines-1 > r.Grid.LineCount() {
+				r.AddPage()
+			}
+			line = r.Cursor.Y
+		}
+		for i := range p.Content {
+			if _, err = p.Content[i].place(r, line, texts[i]); err != nil {
+				return err
+			}
+		}
+		line += rowLines
+		if p.Flow {
+			r.Cursor.Y = line
+		}
+	}
+	return nil
+}
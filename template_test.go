@@ -0,0 +1,113 @@
+package tps
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testTemplate = `
+grid: {orientation: portrait, pageSize: letter, unit: pt, margin: 36, columns: 12, gutter: 12, lineHeight: 12}
+styles:
+  body: {font: Helvetica, size: 10}
+blocks:
+  full: {width: 12, height: 1}
+  name: {width: 8, height: 1}
+  amount: {width: 4, height: 1}
+content:
+  - {x: 1, y: 1, block: full, style: body, text: "Invoice {{.Number}}"}
+  - range: .Items
+    y: 3
+    content:
+      - {x: 1, block: name, style: body, text: "{{.Name}}"}
+      - {x: 9, block: amount, style: body, text: "{{printf \"%.2f\" .Amount}}"}
+  - range: .Notes
+    flow: true
+    content:
+      - {x: 1, block: full, style: body, text: "{{.}}"}
+`
+
+type testInvoice struct {
+	Number int
+	Items  []testItem
+	Notes  map[string]string
+}
+
+type testItem struct {
+	Name   string
+	Amount float64
+}
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate(strings.NewReader(testTemplate))
+	if err != nil {
+		t.Fatalf("ParseTemplate returned an error: %v", err)
+	}
+	invoice := testInvoice{
+		Number: 42,
+		Items:  []testItem{{"Apples", 1}, {"Pears", 2}, {"Plums", 3}},
+		Notes:  map[string]string{"b": "second", "a": "first"},
+	}
+	r, err := tmpl.Execute(invoice)
+	if err != nil {
+		t.Fatalf("Execute returned an error: %v", err)
+	}
+	if r.Cursor.Y != 3 {
+		t.Errorf("Execute did not flow the ranged rows. Got cursor %d expected %d", r.Cursor.Y, 3)
+	}
+
+	// the template can be executed again with different data
+	invoice.Notes = nil
+	if r, err = tmpl.Execute(invoice); err != nil || r.Cursor.Y != 1 {
+		t.Errorf("Execute did not run again with other data. Got cursor %d error %v", r.Cursor.Y, err)
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	var specErr *SpecError
+	bad := strings.Replace(testTemplate, "{{.Name}}", "{{.Name", 1)
+	if _, err := ParseTemplate(strings.NewReader(bad)); !errors.As(err, &specErr) || specErr.Line != 14 {
+		t.Errorf("ParseTemplate did not return the position of a bad template. Got %v", err)
+	}
+
+	tmpl, _ := ParseTemplate(strings.NewReader(testTemplate))
+	_, err := tmpl.Execute(map[string]interface{}{"Number": 1, "Items": []testItem{}})
+	if !errors.As(err, &specErr) || specErr.Line != 16 {
+		t.Errorf("Execute did not return the position of a failed range. Got %v", err)
+	}
+	_, err = tmpl.Execute(map[string]interface{}{"Number": 1, "Items": true})
+	if !errors.As(err, &specErr) || specErr.Line != 11 {
+		t.Errorf("Execute did not return the position of a bad range value. Got %v", err)
+	}
+
+	if _, err = LoadSpec(strings.NewReader(testTemplate)); err == nil {
+		t.Errorf("LoadSpec did not return an error for a range outside a template")
+	}
+}
+
+func TestTemplateRangeMapOrder(t *testing.T) {
+	spec := `
+grid: {orientation: portrait, pageSize: letter, unit: pt, margin: 36, columns: 12, gutter: 12, lineHeight: 12}
+styles:
+  body: {font: Helvetica, size: 10}
+blocks:
+  full: {width: 12, height: 1}
+content:
+  - range: .
+    content:
+      - {x: 1, block: full, style: body, text: "{{.}}"}
+`
+	tmpl, err := ParseTemplate(strings.NewReader(spec))
+	if err != nil {
+		t.Fatalf("ParseTemplate returned an error: %v", err)
+	}
+	p := &tmpl.spec.Content[0]
+	items, err := tmpl.items(p, map[int]string{10: "ten", 2: "two", 1: "one"})
+	if err != nil {
+		t.Fatalf("items returned an error: %v", err)
+	}
+	if got := fmt.Sprint(items); got != "[one two ten]" {
+		t.Errorf("items did not order int keys numerically. Got %s", got)
+	}
+}
@@ -9,6 +9,8 @@
 // of horizontal cells to take up).
 package tps
 
+import "github.com/jung-kurt/gofpdf"
+
 const (
 	OrientationPortrait = iota
 	OrientationLandscape
@@ -20,6 +22,12 @@ const (
 	PageSizeA5
 	PageSizeLetter
 	PageSizeLegal
+	PageSizeTabloid
+	PageSizeB4
+	PageSizeB5
+	PageSizeExecutive
+	// PageSizeCustom uses Grid.CustomWidth and Grid.CustomHeight.
+	PageSizeCustom
 )
 
 const (
@@ -39,7 +47,28 @@ const (
 	AlignBottom
 )
 
-var alignment, orientation, pageSize, unit map[int]string
+const (
+	BorderLeft = 1 << iota
+	BorderTop
+	BorderRight
+	BorderBottom
+	BorderAll = BorderLeft | BorderTop | BorderRight | BorderBottom
+)
+
+const (
+	ImageFit = iota
+	ImageFill
+	ImageStretch
+)
+
+var alignment, border, orientation, pageSize, unit map[int]string
+
+// pageDimensions are the portrait width and height of each standard page size
+// in points.
+var pageDimensions map[int]gofpdf.SizeType
+
+// unitPoints is the # of points in each unit.
+var unitPoints map[int]float64
 
 func init() {
 	alignment = map[int]string{
@@ -50,16 +79,38 @@ func init() {
 		AlignMiddle: "M",
 		AlignBottom: "B",
 	}
+	border = map[int]string{
+		BorderLeft:   "L",
+		BorderTop:    "T",
+		BorderRight:  "R",
+		BorderBottom: "B",
+	}
 	orientation = map[int]string{
 		OrientationPortrait:  "Portrait",
 		OrientationLandscape: "Landscape",
 	}
 	pageSize = map[int]string{
-		PageSizeA3:     "A3",
-		PageSizeA4:     "A4",
-		PageSizeA5:     "A5",
-		PageSizeLetter: "Letter",
-		PageSizeLegal:  "Legal",
+		PageSizeA3:        "A3",
+		PageSizeA4:        "A4",
+		PageSizeA5:        "A5",
+		PageSizeLetter:    "Letter",
+		PageSizeLegal:     "Legal",
+		PageSizeTabloid:   "Tabloid",
+		PageSizeB4:        "B4",
+		PageSizeB5:        "B5",
+		PageSizeExecutive: "Executive",
+		PageSizeCustom:    "Custom",
+	}
+	pageDimensions = map[int]gofpdf.SizeType{
+		PageSizeA3:        {Wd: 841.89, Ht: 1190.55},
+		PageSizeA4:        {Wd: 595.28, Ht: 841.89},
+		PageSizeA5:        {Wd: 420.94, Ht: 595.28},
+		PageSizeLetter:    {Wd: 612, Ht: 792},
+		PageSizeLegal:     {Wd: 612, Ht: 1008},
+		PageSizeTabloid:   {Wd: 792, Ht: 1224},
+		PageSizeB4:        {Wd: 708.66, Ht: 1000.63},
+		PageSizeB5:        {Wd: 498.9, Ht: 708.66},
+		PageSizeExecutive: {Wd: 522, Ht: 756},
 	}
 	unit = map[int]string{
 		UnitPt: "pt",
@@ -67,4 +118,10 @@ func init() {
 		UnitCm: "cm",
 		UnitIn: "in",
 	}
+	unitPoints = map[int]float64{
+		UnitPt: 1,
+		UnitMm: 72 / 25.4,
+		UnitCm: 72 / 2.54,
+		UnitIn: 72,
+	}
 }
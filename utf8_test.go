@@ -0,0 +1,65 @@
+package tps
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func newUTF8Report(t *testing.T) *Report {
+	dir := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(dir, "Go-Regular.ttf"), goregular.TTF, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewReport(
+		WithFontPath(dir),
+		WithGrid(OrientationPortrait, PageSizeLetter, UnitPt, 36.0, 12, 12.0, 12.0),
+		WithStyles(map[string]Style{"body": {FontFamily: "Go", FontSize: 10, Alignment: AlignLeft}}),
+		WithBlocks(map[string]Block{"full": {12, 1}}),
+	)
+	if err != nil {
+		t.Fatalf("NewReport returned an error: %v", err)
+	}
+	if err = r.AddUTF8Font("Go", "", "Go-Regular.ttf"); err != nil {
+		t.Fatalf("AddUTF8Font returned an error: %v", err)
+	}
+	r.AddPage()
+	return r
+}
+
+func TestAddUTF8Font(t *testing.T) {
+	r := newUTF8Report(t)
+	lineCount, err := r.Flow("full", "body", "Zażółć gęślą jaźń\nΚαλημέρα κόσμε\nПривет")
+	if err != nil {
+		t.Fatalf("Flow returned an error for UTF-8 text: %v", err)
+	}
+	if lineCount != 3 {
+		t.Errorf("Flow returned wrong line count. Got %d expected %d", lineCount, 3)
+	}
+	if _, err = r.Bytes(); err != nil {
+		t.Errorf("Bytes returned an error with a UTF-8 font: %v", err)
+	}
+}
+
+func TestAddUTF8FontMissingGlyph(t *testing.T) {
+	r := newUTF8Report(t)
+	if _, err := r.Content(1, 1, "full", "body", "漢字"); err == nil {
+		t.Errorf("Content did not return an error for a missing glyph")
+	}
+	if err := r.AddUTF8Font("Missing", "", "Missing.ttf"); err == nil {
+		t.Errorf("AddUTF8Font did not return an error for a missing file")
+	}
+}
+
+func TestAddFontWithoutGrid(t *testing.T) {
+	r, _ := NewReport()
+	if err := r.AddUTF8Font("Go", "", "Go-Regular.ttf"); err == nil {
+		t.Errorf("AddUTF8Font did not return an error without a grid")
+	}
+	if err := r.AddFont("Go-Regular.ttf", "cp1252"); err == nil {
+		t.Errorf("AddFont did not return an error without a grid")
+	}
+}